@@ -15,6 +15,13 @@ const (
 	// OpenAPI version supported by this package.
 	Ver = `3.1.0`
 
+	/**
+	Later OpenAPI version, partially supported by this package. Some features,
+	such as `oas.MediaType.ItemSchema`, are used only when `oas.Doc.Openapi`
+	targets this version.
+	*/
+	Ver32 = `3.2.0`
+
 	TypeNull = `null`
 	TypeInt  = `integer`
 	TypeNum  = `number`
@@ -54,5 +61,27 @@ const (
 	InHeader = `header`
 	InCookie = `cookie`
 
-	ConTypeJson = `application/json`
+	/**
+	HTTP method for safe requests with a body, described by `oas.Path.Query`,
+	added in OpenAPI 3.2. Reference:
+
+		https://datatracker.ietf.org/doc/draft-ietf-httpbis-safe-method-w-body/
+	*/
+	MethodQuery = `QUERY`
+
+	ConTypeJson      = `application/json`
+	ConTypeForm      = `application/x-www-form-urlencoded`
+	ConTypeMultipart = `multipart/form-data`
+	ConTypeBin       = `application/octet-stream`
+	ConTypeNdjson    = `application/x-ndjson`
+	ConTypeJsonl     = `application/jsonl`
+	ConTypeSse       = `text/event-stream`
+	ConTypeXml       = `application/xml`
+	ConTypeCsv       = `text/csv`
+
+	// Content type of RFC 9457 problem details. See `oas.Doc.ProblemSchema`.
+	ConTypeProblem = `application/problem+json`
+
+	// Response header describing file downloads. See `oas.FileResp`.
+	HeadConDisp = `Content-Disposition`
 )
@@ -0,0 +1,78 @@
+package oas
+
+/*
+Describes a raw binary payload of any of the given media types, such as
+"image/png", as recommended by OAS 3.1: a media type without a schema. Without
+arguments, uses "application/octet-stream". Reference:
+
+	https://spec.openapis.org/oas/v3.1.0#considerations-for-file-uploads
+*/
+func BinMedia(conTypes ...string) MediaTypes {
+	if len(conTypes) == 0 {
+		conTypes = []string{ConTypeBin}
+	}
+
+	out := make(MediaTypes, len(conTypes))
+	for _, val := range conTypes {
+		out[val] = MediaType{}
+	}
+	return out
+}
+
+/*
+Returns a schema of a base64-encoded string containing content of the given
+media type, for binary data embedded in text formats such as JSON. If the
+media type is empty, it's omitted.
+*/
+func Base64Schema(conType string) Schema {
+	return Schema{Type: []string{TypeStr}, ContEnc: `base64`, ContMedia: conType}
+}
+
+/*
+Returns a required request body with a raw binary payload of any of the given
+media types. See `oas.BinMedia`.
+*/
+func BinBody(conTypes ...string) Body {
+	return Body{Cont: BinMedia(conTypes...), Requ: true}
+}
+
+/*
+Returns a response with a raw binary body of any of the given media types,
+such as "text/csv". See `oas.BinMedia`. If the description is empty, uses a
+generic one.
+*/
+func BinResp(desc string, conTypes ...string) Resp {
+	if desc == `` {
+		desc = `Binary content.`
+	}
+	return Resp{Desc: desc, Cont: BinMedia(conTypes...)}
+}
+
+/*
+Like `oas.BinResp`, but describes a file download: the response has a
+required "Content-Disposition" header, which typically specifies the file
+name, such as `attachment; filename="report.pdf"`.
+*/
+func FileResp(desc string, conTypes ...string) Resp {
+	if desc == `` {
+		desc = `File download.`
+	}
+	out := BinResp(desc, conTypes...)
+	out.Head = Heads{HeadConDisp: {
+		Desc:    `Disposition of the content, such as "attachment", with the suggested file name.`,
+		Requ:    true,
+		Schema:  &Schema{Type: []string{TypeStr}},
+		Example: `attachment; filename="file"`,
+	}}
+	return out
+}
+
+// Adds a binary response for the given status. See `oas.BinResp`.
+func (self *RespsBuilder) Bin(status, desc string, conTypes ...string) *RespsBuilder {
+	return self.Status(status, BinResp(desc, conTypes...))
+}
+
+// Adds a file download response for the given status. See `oas.FileResp`.
+func (self *RespsBuilder) File(status, desc string, conTypes ...string) *RespsBuilder {
+	return self.Status(status, FileResp(desc, conTypes...))
+}
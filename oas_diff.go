@@ -0,0 +1,91 @@
+package oas
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Change between two documents, produced by `oas.Diff`.
+type Change struct {
+	// Short machine-readable identifier, such as "op-removed".
+	Code string
+
+	// True if the change may break existing clients.
+	Breaking bool
+
+	/**
+	JSON Pointer to the changed location. Refers to the new document, or to the
+	old document for removals. Since references are followed, the pointer is
+	logical: it may continue past a `$ref`, for example into the properties of
+	a referenced schema.
+	*/
+	Ptr string
+
+	// Human-readable description.
+	Msg string
+}
+
+// Implement `fmt.Stringer`.
+func (self Change) String() string {
+	kind := `non-breaking`
+	if self.Breaking {
+		kind = `breaking`
+	}
+	return fmt.Sprintf(`%v at %q: %v (%v)`, kind, self.Ptr, self.Msg, self.Code)
+}
+
+// List of changes produced by `oas.Diff`.
+type Changes []Change
+
+// Returns only the breaking changes.
+func (self Changes) Breaking() (out Changes) {
+	for _, val := range self {
+		if val.Breaking {
+			out = append(out, val)
+		}
+	}
+	return
+}
+
+// Human-readable report, one change per line.
+func (self Changes) String() string {
+	var buf strings.Builder
+	for _, val := range self {
+		buf.WriteString(val.String())
+		buf.WriteByte('\n')
+	}
+	return buf.String()
+}
+
+/*
+Compares two versions of a document, typically the previous release and the
+newly generated one, and classifies changes to operations as breaking or
+non-breaking for clients. Detects:
+
+  - Added and removed operations.
+  - Added, removed, and newly required parameters and request bodies.
+  - Added and removed responses, media types and response headers, and
+    response headers which became optional.
+  - Schema changes: type changes, enum changes, added and removed properties
+    and items schemas, and changes of `required`.
+
+Schemas are compared in context: for example, a narrowed enum breaks requests
+but not responses, while a removed property breaks responses but not requests.
+References are followed in both documents, so renaming a component without
+changing its content is not a change. Subschemas of compositions (`allOf`,
+`anyOf`, `oneOf`) are paired by reference or content, so reordering is not a
+change; adding an `anyOf` or `oneOf` variant breaks responses but not
+requests, while adding to `allOf` is the opposite. Changes of a referenced
+schema are reported at every location which uses it. Changes in
+documentation, such as descriptions, are ignored.
+
+Known limitations: webhooks and callbacks are not compared, and neither are
+schema constraints such as formats, lengths and numeric limits.
+*/
+func Diff(prev, next *Doc) Changes {
+	var diff differ
+	diff.prev, diff.next = prev, next
+	diff.refs = map[differRefs]*differRef{}
+	diff.paths()
+	return diff.out
+}
@@ -0,0 +1,431 @@
+package oas
+
+import (
+	"fmt"
+	r "reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+type differDir byte
+
+const (
+	differReq differDir = iota
+	differResp
+)
+
+type differRefs struct {
+	prev, next string
+	dir        differDir
+}
+
+/*
+Changes between referenced schemas, with pointers relative to the schema.
+Computed once per pair of references and reported at every use site.
+*/
+type differRef struct {
+	done bool
+	out  Changes
+}
+
+type differ struct {
+	prev, next *Doc
+	refs       map[differRefs]*differRef
+	out        Changes
+}
+
+func (self *differ) add(code string, breaking bool, ptr, msg string) {
+	self.out = append(self.out, Change{Code: code, Breaking: breaking, Ptr: ptr, Msg: msg})
+}
+
+func (self *differ) paths() {
+	for _, key := range unionKeys(self.prev.Paths, self.next.Paths) {
+		prevPath := self.prev.Paths[key]
+		nextPath := self.next.Paths[key]
+
+		for _, meth := range pathMethodsOf(prevPath, nextPath) {
+			prev := prevPath.Op(meth)
+			next := nextPath.Op(meth)
+			ptr := pathOpPtr(ptrAppend(`/paths`, key), meth)
+
+			if prev != nil && next == nil {
+				self.add(`op-removed`, true, ptr, `removed operation `+meth+` `+key)
+			} else if prev == nil && next != nil {
+				self.add(`op-added`, false, ptr, `added operation `+meth+` `+key)
+			} else if prev != nil && next != nil {
+				self.op(ptr, key, &prevPath, &nextPath, prev, next)
+			}
+		}
+	}
+}
+
+func (self *differ) op(ptr, path string, prevPath, nextPath *Path, prev, next *Op) {
+	self.params(ptr, path, prevPath, nextPath, prev, next)
+	self.body(ptrAppend(ptr, `requestBody`), prev.ReqBody, next.ReqBody)
+
+	respsPtr := ptrAppend(ptr, `responses`)
+	for _, key := range unionKeys(prev.Resps, next.Resps) {
+		prevResp, okPrev := prev.Resps[key]
+		nextResp, okNext := next.Resps[key]
+		ptr := ptrAppend(respsPtr, key)
+
+		if okPrev && !okNext {
+			success := strings.HasPrefix(key, `2`) || key == `default`
+			self.add(`resp-removed`, success, ptr, `removed response `+strconv.Quote(key))
+		} else if !okPrev && okNext {
+			self.add(`resp-added`, false, ptr, `added response `+strconv.Quote(key))
+		} else {
+			prevResp = derefAs(self.prev, prevResp, prevResp.Ref)
+			nextResp = derefAs(self.next, nextResp, nextResp.Ref)
+			self.heads(ptrAppend(ptr, `headers`), prevResp.Head, nextResp.Head)
+			self.media(ptrAppend(ptr, `content`), prevResp.Cont, nextResp.Cont, differResp)
+		}
+	}
+}
+
+/*
+Compares response headers. Clients may rely on any documented header, so
+removing one is breaking, like removing a response property. The spec requires
+ignoring the "Content-Type" header definition.
+*/
+func (self *differ) heads(ptr string, prev, next Heads) {
+	for _, key := range unionKeys(prev, next) {
+		if strings.EqualFold(key, `Content-Type`) {
+			continue
+		}
+
+		prevHead, okPrev := prev[key]
+		nextHead, okNext := next[key]
+		ptr := ptrAppend(ptr, key)
+
+		if okPrev && !okNext {
+			self.add(`header-removed`, true, ptr, `removed response header `+strconv.Quote(key))
+		} else if !okPrev && okNext {
+			self.add(`header-added`, false, ptr, `added response header `+strconv.Quote(key))
+		} else {
+			prevHead = derefAs(self.prev, prevHead, prevHead.Ref)
+			nextHead = derefAs(self.next, nextHead, nextHead.Ref)
+			if prevHead.Requ && !nextHead.Requ {
+				self.add(`header-optional`, true, ptr, `response header `+strconv.Quote(key)+` became optional`)
+			}
+			if prevHead.Schema != nil && nextHead.Schema != nil {
+				self.schema(ptrAppend(ptr, `schema`), *prevHead.Schema, *nextHead.Schema, differResp)
+			}
+		}
+	}
+}
+
+type differParam struct {
+	Param
+	ptr string
+}
+
+func (self *differ) params(ptr, path string, prevPath, nextPath *Path, prev, next *Op) {
+	prevParams := self.opParams(self.prev, ptr, path, prevPath, prev)
+	nextParams := self.opParams(self.next, ptr, path, nextPath, next)
+
+	for _, key := range unionKeys(prevParams, nextParams) {
+		prevParam, okPrev := prevParams[key]
+		nextParam, okNext := nextParams[key]
+		desc := `parameter ` + strconv.Quote(nextParam.Name) + ` in ` + nextParam.In
+
+		if okPrev && !okNext {
+			desc = `parameter ` + strconv.Quote(prevParam.Name) + ` in ` + prevParam.In
+			self.add(`param-removed`, false, prevParam.ptr, `removed `+desc)
+		} else if !okPrev && okNext {
+			if nextParam.Requ {
+				self.add(`param-added`, true, nextParam.ptr, `added required `+desc)
+			} else {
+				self.add(`param-added`, false, nextParam.ptr, `added optional `+desc)
+			}
+		} else {
+			if !prevParam.Requ && nextParam.Requ {
+				self.add(`param-required`, true, nextParam.ptr, desc+` became required`)
+			}
+			if prevParam.Schema != nil && nextParam.Schema != nil {
+				self.schema(ptrAppend(nextParam.ptr, `schema`), *prevParam.Schema, *nextParam.Schema, differReq)
+			}
+		}
+	}
+}
+
+/*
+Parameters of the operation, including path-level parameters, keyed by
+location and name. Operation-level parameters override path-level ones.
+*/
+func (*differ) opParams(doc *Doc, ptr, path string, item *Path, op *Op) map[string]differParam {
+	out := map[string]differParam{}
+	add := func(ptr string, list []Param) {
+		for ind, val := range list {
+			val = derefAs(doc, val, val.Ref)
+			out[val.In+`:`+val.Name] = differParam{val, ptrAppend(ptr, strconv.Itoa(ind))}
+		}
+	}
+	add(ptrAppend(ptrAppend(`/paths`, path), `parameters`), item.Params)
+	add(ptrAppend(ptr, `parameters`), op.Params)
+	return out
+}
+
+func (self *differ) body(ptr string, prev, next *Body) {
+	if prev != nil {
+		prev = derefAs(self.prev, *prev, prev.Ref).Opt()
+	}
+	if next != nil {
+		next = derefAs(self.next, *next, next.Ref).Opt()
+	}
+
+	if prev != nil && next == nil {
+		self.add(`body-removed`, false, ptr, `removed request body`)
+		return
+	}
+	if next == nil {
+		return
+	}
+	if prev == nil {
+		self.add(`body-added`, next.Requ, ptr, `added request body`)
+		return
+	}
+	if !prev.Requ && next.Requ {
+		self.add(`body-required`, true, ptr, `request body became required`)
+	}
+	self.media(ptrAppend(ptr, `content`), prev.Cont, next.Cont, differReq)
+}
+
+func (self *differ) media(ptr string, prev, next MediaTypes, dir differDir) {
+	for _, key := range unionKeys(prev, next) {
+		prevMedia, okPrev := prev[key]
+		nextMedia, okNext := next[key]
+		ptr := ptrAppend(ptr, key)
+
+		if okPrev && !okNext {
+			self.add(`media-removed`, true, ptr, `removed media type `+strconv.Quote(key))
+		} else if !okPrev && okNext {
+			self.add(`media-added`, false, ptr, `added media type `+strconv.Quote(key))
+		} else {
+			self.schema(ptrAppend(ptr, `schema`), prevMedia.Schema, nextMedia.Schema, dir)
+		}
+	}
+}
+
+func (self *differ) schema(ptr string, prev, next Schema, dir differDir) {
+	key := differRefs{prev.Ref, next.Ref, dir}
+	if key.prev == `` && key.next == `` {
+		self.schemaDeref(ptr, prev, next, dir)
+		return
+	}
+
+	ref := self.refs[key]
+	if ref == nil {
+		ref = &differRef{}
+		self.refs[key] = ref
+
+		out := self.out
+		self.out = nil
+		self.schemaDeref(``, derefAs(self.prev, prev, prev.Ref), derefAs(self.next, next, next.Ref), dir)
+		ref.out, self.out = self.out, out
+		ref.done = true
+	} else if !ref.done {
+		// Recursive schema: changes are reported at the outer use site.
+		return
+	}
+
+	for _, val := range ref.out {
+		val.Ptr = ptr + val.Ptr
+		self.out = append(self.out, val)
+	}
+}
+
+func (self *differ) schemaDeref(ptr string, prev, next Schema, dir differDir) {
+	req := dir == differReq
+
+	if len(prev.Type) > 0 && len(next.Type) > 0 {
+		removed := stringsDiff(prev.Type, next.Type)
+		added := stringsDiff(next.Type, prev.Type)
+		if len(removed) > 0 || len(added) > 0 {
+			self.add(
+				`type-changed`,
+				(req && len(removed) > 0) || (!req && len(added) > 0),
+				ptrAppend(ptr, `type`),
+				fmt.Sprintf(`type changed from %q to %q`, prev.Type, next.Type),
+			)
+		}
+	}
+
+	self.enum(ptrAppend(ptr, `enum`), prev.Enum, next.Enum, req)
+
+	for _, key := range stringsDiff(next.Requ, prev.Requ) {
+		self.add(`required-added`, req, ptrAppend(ptrAppend(ptr, `properties`), key), `property `+strconv.Quote(key)+` became required`)
+	}
+	for _, key := range stringsDiff(prev.Requ, next.Requ) {
+		self.add(`required-removed`, !req, ptrAppend(ptrAppend(ptr, `properties`), key), `property `+strconv.Quote(key)+` became optional`)
+	}
+
+	for _, key := range unionKeys(prev.Props, next.Props) {
+		prevProp, okPrev := prev.Props[key]
+		nextProp, okNext := next.Props[key]
+		ptr := ptrAppend(ptrAppend(ptr, `properties`), key)
+
+		if okPrev && !okNext {
+			self.add(`prop-removed`, !req, ptr, `removed property `+strconv.Quote(key))
+		} else if !okPrev && okNext {
+			self.add(`prop-added`, false, ptr, `added property `+strconv.Quote(key))
+		} else {
+			self.schema(ptr, prevProp, nextProp, dir)
+		}
+	}
+
+	// Like a missing enum, missing items allow any value.
+	if prev.Items == nil && next.Items != nil {
+		self.add(`items-added`, req, ptrAppend(ptr, `items`), `added items schema`)
+	} else if prev.Items != nil && next.Items == nil {
+		self.add(`items-removed`, !req, ptrAppend(ptr, `items`), `removed items schema`)
+	} else if prev.Items != nil && next.Items != nil {
+		self.schema(ptrAppend(ptr, `items`), *prev.Items, *next.Items, dir)
+	}
+	if prev.AddProps != nil && next.AddProps != nil {
+		self.schema(ptrAppend(ptr, `additionalProperties`), *prev.AddProps, *next.AddProps, dir)
+	}
+
+	self.schemas(ptr, `allOf`, prev.AllOf, next.AllOf, dir)
+	self.schemas(ptr, `anyOf`, prev.AnyOf, next.AnyOf, dir)
+	self.schemas(ptr, `oneOf`, prev.OneOf, next.OneOf, dir)
+}
+
+/*
+Compares subschemas of a composition keyword. Subschemas are paired by
+reference, then by equality, then by position among the remaining ones.
+Adding an "allOf" subschema narrows the set of allowed values, while adding
+an "anyOf" or "oneOf" variant widens it, unless there were no variants, in
+which case any value was allowed. Removals are the opposite.
+*/
+func (self *differ) schemas(ptr, kw string, prev, next []Schema, dir differDir) {
+	ptr = ptrAppend(ptr, kw)
+	req := dir == differReq
+	narrows := kw == `allOf`
+
+	pairs := schemasPair(prev, next)
+	for ind, val := range pairs {
+		if val >= 0 {
+			self.schema(ptrAppend(ptr, strconv.Itoa(val)), prev[ind], next[val], dir)
+		}
+	}
+
+	for ind, val := range pairs {
+		if val < 0 {
+			breaking := (narrows || len(next) == 0) != req
+			self.add(`subschema-removed`, breaking, ptrAppend(ptr, strconv.Itoa(ind)), `removed subschema from `+strconv.Quote(kw))
+		}
+	}
+
+	for ind := range next {
+		if !intsHas(pairs, ind) {
+			breaking := (narrows || len(prev) == 0) == req
+			self.add(`subschema-added`, breaking, ptrAppend(ptr, strconv.Itoa(ind)), `added subschema to `+strconv.Quote(kw))
+		}
+	}
+}
+
+/*
+For each of the previous subschemas, returns the index of the matching next
+subschema, or -1.
+*/
+func schemasPair(prev, next []Schema) []int {
+	out := make([]int, len(prev))
+	used := make([]bool, len(next))
+
+	match := func(fun func(Schema, Schema) bool) {
+		for ind, val := range prev {
+			if out[ind] >= 0 {
+				continue
+			}
+			for nextInd, nextVal := range next {
+				if !used[nextInd] && fun(val, nextVal) {
+					out[ind], used[nextInd] = nextInd, true
+					break
+				}
+			}
+		}
+	}
+
+	for ind := range out {
+		out[ind] = -1
+	}
+	match(func(prev, next Schema) bool { return prev.Ref != `` && prev.Ref == next.Ref })
+	match(func(prev, next Schema) bool { return r.DeepEqual(prev, next) })
+	match(func(prev, next Schema) bool { return true })
+	return out
+}
+
+func intsHas(list []int, val int) bool {
+	for _, elem := range list {
+		if elem == val {
+			return true
+		}
+	}
+	return false
+}
+
+/*
+A missing enum allows any value, so adding an enum narrows the set of allowed
+values, while removing it widens the set.
+*/
+func (self *differ) enum(ptr string, prev, next []any, req bool) {
+	removed := anysDiff(prev, next)
+	added := anysDiff(next, prev)
+
+	if (prev == nil && next != nil) || (next != nil && len(removed) > 0) {
+		self.add(`enum-narrowed`, req, ptr, fmt.Sprintf(`enum narrowed, removed values: %v`, removed))
+	}
+	if (prev != nil && next == nil) || (prev != nil && len(added) > 0) {
+		self.add(`enum-widened`, !req, ptr, fmt.Sprintf(`enum widened, added values: %v`, added))
+	}
+}
+
+/*
+Follows the reference, if any, returning the target if it's of the same type.
+Unresolvable references are treated as empty objects.
+*/
+func derefAs[A any](doc *Doc, val A, ref string) A {
+	if ref == `` {
+		return val
+	}
+	out, _ := ResolveAs[A](doc, ref)
+	return out
+}
+
+func unionKeys[A any](one, two map[string]A) []string {
+	out := sortedKeys(one)
+	for key := range two {
+		_, ok := one[key]
+		if !ok {
+			out = append(out, key)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+// Elements of `one` missing from `two`.
+func stringsDiff(one, two []string) (out []string) {
+	for _, val := range one {
+		if !stringsContain(two, val) {
+			out = append(out, val)
+		}
+	}
+	return
+}
+
+// Elements of `one` missing from `two`, compared deeply.
+func anysDiff(one, two []any) (out []any) {
+outer:
+	for _, val := range one {
+		for _, other := range two {
+			if r.DeepEqual(val, other) {
+				continue outer
+			}
+		}
+		out = append(out, val)
+	}
+	return
+}
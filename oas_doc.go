@@ -3,6 +3,7 @@ package oas
 import (
 	"fmt"
 	r "reflect"
+	"strings"
 )
 
 /*
@@ -12,7 +13,7 @@ Top-level OpenAPI document. Reference:
 */
 type Doc struct {
 	Ref        string   `json:"$ref,omitempty"        yaml:"$ref,omitempty"        toml:"$ref,omitempty"`
-	Sum        string   `json:"sum,omitempty"         yaml:"sum,omitempty"         toml:"sum,omitempty"`
+	Sum        string   `json:"summary,omitempty"     yaml:"summary,omitempty"     toml:"summary,omitempty"`
 	Desc       string   `json:"description,omitempty" yaml:"description,omitempty" toml:"description,omitempty"`
 	Openapi    string   `json:"openapi,omitempty"           yaml:"openapi,omitempty"           toml:"openapi,omitempty"`
 	Info       *Info    `json:"info,omitempty"              yaml:"info,omitempty"              toml:"info,omitempty"`
@@ -24,6 +25,10 @@ type Doc struct {
 	Security   []SecReq `json:"security,omitempty"          yaml:"security,omitempty"          toml:"security,omitempty"`
 	Tags       []Tag    `json:"tags,omitempty"              yaml:"tags,omitempty"              toml:"tags,omitempty"`
 	ExtDoc     *ExtDoc  `json:"externalDocs,omitempty"      yaml:"externalDocs,omitempty"      toml:"externalDocs,omitempty"`
+	Exts       Anys     `json:"-" yaml:",inline" toml:"-"`
+
+	// Extensions of the "Paths" object, encoded inside `.Paths`.
+	PathsExts Anys `json:"-" yaml:"-" toml:"-"`
 }
 
 /*
@@ -53,12 +58,30 @@ func (self *Doc) SchemaMedia(typ interface{}) MediaType {
 	return MediaType{Schema: self.Sch(typ)}
 }
 
-// Shortcut for registering a route via `oas.Doc.Paths.Route`.
+/*
+Shortcut for registering a route via `oas.Doc.Paths.Route`. Panics under the
+same conditions as `oas.Doc.TryRoute`.
+*/
 func (self *Doc) Route(path, meth string, op Op) *Doc {
-	self.Paths.Init().Route(path, meth, op)
+	err := self.TryRoute(path, meth, op)
+	if err != nil {
+		panic(err)
+	}
 	return self
 }
 
+/*
+Shortcut for registering a route via `oas.Doc.Paths.TryRoute`. Also returns an
+error if the method requires OpenAPI 3.2, such as "QUERY" or any method stored
+in `oas.Path.AddOps`, while `.Openapi` targets an earlier version.
+*/
+func (self *Doc) TryRoute(path, meth string, op Op) error {
+	if !self.methodAllowed(meth) {
+		return fmt.Errorf(`[oas] %v`, self.methodVerMsg(meth))
+	}
+	return self.Paths.Init().TryRoute(path, meth, op)
+}
+
 /*
 Looks up a schema by the given name among the doc's components. The name must be
 the exact schema title, not a reference path. May panic if the schema
@@ -73,21 +96,27 @@ func (self *Doc) GotCompSchema(name string) (Schema, bool) {
 }
 
 /*
-Looks up the schema by a full reference path. Currently supports only component
-references starting with `#/components/schemas/`.
+Looks up the schema by a full reference path, which may be any local reference
+supported by `oas.Doc.Resolve`, following chains of references. The bool is
+false if the reference can't be resolved to a schema.
 */
 func (self *Doc) GotSchema(refPath string) (Schema, bool) {
+	// Fast path for the most common case.
 	name, ok := unprefix(refPath, `#/components/schemas/`)
-	if ok {
-		return self.GotCompSchema(name)
+	if ok && !strings.ContainsAny(name, `/~%`) {
+		val, ok := self.Comps.Schemas[name]
+		if ok && val.Ref == `` {
+			return val, true
+		}
 	}
-	panic(fmt.Errorf(`[oas] unsupported schema reference %q`, refPath))
+
+	val, err := ResolveAs[Schema](self, refPath)
+	return val, err == nil
 }
 
 /*
 Dereferences the given schema, returning a non-reference. The bool is true if
-the target schema was found, false otherwise. May panic if the schema
-unexpectedly has double indirection.
+the target schema was found, false otherwise.
 */
 func (self *Doc) DerefSchema(sch Schema) (Schema, bool) {
 	if sch.Ref != `` {
@@ -113,11 +142,13 @@ func (self *Doc) JsonBody(typ interface{}) Body {
 /*
 Shortcut. Returns `oas.Resps` with 200 JSON for the given type, after
 registering its schema in the document. The input is used only as a type
-carrier; its actual value is ignored.
+carrier; its actual value is ignored. For multiple statuses, see
+`oas.Doc.Responses`.
 */
 func (self *Doc) RespsOkJson(typ interface{}) Resps {
 	return Resps{
 		`200`: Resp{
+			Desc: RespDesc(`200`),
 			Cont: MediaTypes{
 				ConTypeJson: {Schema: self.Sch(typ)},
 			},
@@ -158,7 +158,7 @@ func (self *Doc) schemaStructProps(sch *Schema, typ r.Type) {
 	for ind := range iter(typ.NumField()) {
 		field := typ.Field(ind)
 
-		if !isPublic(field.PkgPath) || isTypeSkippable(field.Type) {
+		if !isPublic(field.PkgPath) || isTypeSkippable(field.Type) || isJsonSkipped(field) {
 			continue
 		}
 
@@ -0,0 +1,140 @@
+package oas
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Default for `oas.ExampleOpt.Depth`.
+const ExampleDepth = 8
+
+// Options for `oas.Doc.Example` and `oas.Doc.FillExamples`.
+type ExampleOpt struct {
+	/**
+	Maximum nesting of objects and arrays. Deeper optional properties are
+	omitted, deeper required properties are minimal, and deeper arrays are
+	empty. 0 means `oas.ExampleDepth`.
+	*/
+	Depth int
+
+	/**
+	If true, objects include only the properties listed in "required". Schemas
+	generated from Go types don't list required properties, so by default, all
+	properties are included.
+	*/
+	RequOnly bool
+
+	/**
+	If true, the example describes a request, and "readOnly" properties are
+	omitted. Otherwise it describes a response, and "writeOnly" properties are
+	omitted. Set automatically by `oas.Doc.FillExamples`.
+	*/
+	Req bool
+}
+
+/*
+Generates a plausible example value for the given schema, which may be a
+reference resolved within the document. The result is made of JSON-compatible
+Go values: nil, bool, float64, int64, string, []any, map[string]any.
+
+Uses the first available of "const", "example", "examples", "default" and
+"enum". Otherwise generates a value of the first non-null type, respecting
+"format" (such as "uuid", "date-time" or "email"), numeric and length
+limits, "minItems", "prefixItems", and the subschemas of "allOf", "anyOf" and
+"oneOf", where the first non-null alternative is used. "pattern" is not
+supported. Cyclic references are cut off: an optional property which would
+recurse into its own schema is omitted, while a required one gets a minimal
+value, such as null (when allowed), an empty object or an empty array. Returns
+nil if the schema can't be resolved.
+*/
+func (self *Doc) Example(sch Schema, opt ExampleOpt) any {
+	if opt.Depth <= 0 {
+		opt.Depth = ExampleDepth
+	}
+	val, _ := exampler{self, opt, map[string]bool{}}.any(sch, 0)
+	return val
+}
+
+/*
+Generates examples for JSON media types which don't have any, via
+`oas.Doc.Example`, setting `oas.MediaType.Example`. Applies to request bodies,
+responses, parameters and headers in paths, webhooks and components.
+`oas.ExampleOpt.Req` is set automatically.
+*/
+func (self *Doc) FillExamples(opt ExampleOpt) {
+	_ = self.Walk(func(ptr string, val any) error {
+		media, _ := val.(*MediaType)
+		if media == nil {
+			return nil
+		}
+		if media.Example != nil || len(media.Examples) > 0 || !IsConTypeJson(ptrLast(ptr)) {
+			return WalkSkip
+		}
+
+		opt := opt
+		opt.Req = exampleIsReq(ptr)
+		media.Example = self.Example(media.Schema, opt)
+		return WalkSkip
+	})
+}
+
+/*
+Converts the given Go value to a JSON-compatible value suitable for
+`oas.MediaType.Example` and similar fields, by encoding it via "encoding/json"
+and decoding it back into `any`. Numbers are decoded as `json.Number`,
+preserving the precision of large integers. Panics if the value can't be
+encoded.
+*/
+func ExampleOf(val any) any {
+	src, err := json.Marshal(val)
+	if err != nil {
+		panic(fmt.Errorf(`[oas] failed to encode example of type %T: %w`, val, err))
+	}
+
+	out, err := jsonParse(src)
+	if err != nil {
+		panic(fmt.Errorf(`[oas] failed to decode example of type %T: %w`, val, err))
+	}
+	return out
+}
+
+/*
+Shortcut. Like `oas.Doc.SchemaMedia`, but also uses the given value as the
+example of the media type, encoded via `oas.ExampleOf`. The value's type
+determines the schema, so the example always matches it.
+*/
+func (self *Doc) ExampleMedia(val any) MediaType {
+	return MediaType{Schema: self.Sch(val), Example: ExampleOf(val)}
+}
+
+/*
+Adds an example to the media type, encoded from a Go value via
+`oas.ExampleOf`. If the name is empty, sets `.Example`, otherwise adds an
+entry to `.Examples`. Panics if the encoded value doesn't match the schema of
+the media type, as reported by `oas.Doc.Validate`. This keeps examples in sync
+with types, which is not the case for hand-written JSON. Example:
+
+	media := doc.SchemaMedia(Person{})
+	doc.MediaExample(&media, `ann`, Person{Id: `3a4e`, Name: `Ann`})
+*/
+func (self *Doc) MediaExample(tar *MediaType, name string, val any) {
+	out := self.exampleValid(tar.Schema, val)
+	if name == `` {
+		tar.Example = out
+		return
+	}
+	if tar.Examples == nil {
+		tar.Examples = Examples{}
+	}
+	tar.Examples[name] = Example{Val: out}
+}
+
+/*
+Appends an example to `.Examples` of the schema, encoded from a Go value via
+`oas.ExampleOf`. If the schema is a reference, the example is added to the
+reference, which is allowed by JSON Schema 2020-12. Panics if the encoded value
+doesn't match the schema, as reported by `oas.Doc.Validate`.
+*/
+func (self *Doc) SchemaExample(tar *Schema, val any) {
+	tar.Examples = append(tar.Examples, self.exampleValid(*tar, val))
+}
@@ -0,0 +1,330 @@
+package oas
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+type exampler struct {
+	doc *Doc
+	ExampleOpt
+
+	// References being generated, for cutting off cycles.
+	refs map[string]bool
+}
+
+/*
+Generates an example for the schema. The bool is false if the value can't be
+generated because of a reference cycle, excessive depth or a `false` schema,
+in which case the caller omits the value.
+*/
+func (self exampler) any(sch Schema, depth int) (any, bool) {
+	if depth > self.Depth {
+		return nil, false
+	}
+	if sch.Bool != nil {
+		return nil, *sch.Bool
+	}
+
+	val, ok := exampleAnnotated(sch)
+	if ok {
+		return val, true
+	}
+
+	if sch.Ref != `` {
+		if self.refs[sch.Ref] {
+			return nil, false
+		}
+		tar, err := ResolveAs[Schema](self.doc, sch.Ref)
+		if err != nil {
+			return nil, true
+		}
+
+		self.refs[sch.Ref] = true
+		defer delete(self.refs, sch.Ref)
+		return self.any(tar, depth)
+	}
+
+	if len(sch.AllOf) > 0 {
+		return self.allOf(sch, depth)
+	}
+
+	for _, list := range [][]Schema{sch.OneOf, sch.AnyOf} {
+		for _, alt := range list {
+			if alt.TypeIs(TypeNull) {
+				continue
+			}
+			val, ok := self.any(alt, depth)
+			if ok {
+				return val, true
+			}
+		}
+	}
+
+	switch exampleType(sch) {
+	case TypeBool:
+		return true, true
+	case TypeInt:
+		return int64(exampleNum(sch, 1, true)), true
+	case TypeNum:
+		return exampleNum(sch, 1.5, false), true
+	case TypeStr:
+		return exampleStr(sch), true
+	case TypeArr:
+		return self.array(sch, depth), true
+	case TypeObj:
+		return self.object(sch, depth), true
+	}
+	return nil, true
+}
+
+// Merges the examples of "allOf" subschemas, which are normally objects.
+func (self exampler) allOf(sch Schema, depth int) (any, bool) {
+	rest := sch
+	rest.AllOf = nil
+	out, _ := self.any(rest, depth)
+
+	for _, sub := range sch.AllOf {
+		val, ok := self.any(sub, depth)
+		if !ok {
+			return nil, false
+		}
+
+		outMap, _ := out.(map[string]any)
+		valMap, _ := val.(map[string]any)
+		if outMap != nil && valMap != nil {
+			for key, val := range valMap {
+				outMap[key] = val
+			}
+		} else if out == nil {
+			out = val
+		}
+	}
+	return out, true
+}
+
+func (self exampler) array(sch Schema, depth int) []any {
+	out := []any{}
+
+	for _, item := range sch.PrefixItems {
+		val, ok := self.any(item, depth+1)
+		if !ok {
+			return out
+		}
+		out = append(out, val)
+	}
+
+	if sch.Items == nil || sch.Items.Bool != nil {
+		return out
+	}
+
+	count := sch.MinItems
+	if count == 0 {
+		count = 1
+	}
+	if sch.MaxItems > 0 && count > sch.MaxItems {
+		count = sch.MaxItems
+	}
+
+	for uint64(len(out)) < count {
+		val, ok := self.any(*sch.Items, depth+1)
+		if !ok {
+			break
+		}
+		out = append(out, val)
+	}
+	return out
+}
+
+func (self exampler) object(sch Schema, depth int) map[string]any {
+	out := map[string]any{}
+
+	for _, key := range sortedKeys(sch.Props) {
+		prop := sch.Props[key]
+		if self.RequOnly && !stringsContain(sch.Requ, key) {
+			continue
+		}
+		if self.skip(prop) {
+			continue
+		}
+
+		val, ok := self.any(prop, depth+1)
+		if ok {
+			out[key] = val
+		} else if stringsContain(sch.Requ, key) && prop.Bool == nil {
+			out[key] = self.minimal(prop)
+		}
+	}
+
+	if len(sch.Props) == 0 && sch.AddProps != nil && sch.AddProps.Bool == nil {
+		val, ok := self.any(*sch.AddProps, depth+1)
+		if ok {
+			out[`key`] = val
+		}
+	}
+	return out
+}
+
+/*
+Value for a required property which was cut off by a reference cycle or by the
+depth limit: null if allowed, otherwise an empty value of the schema's type.
+*/
+func (self exampler) minimal(sch Schema) any {
+	sch, _ = self.doc.DerefSchema(sch)
+	if sch.TypeHas(TypeNull) {
+		return nil
+	}
+	for _, list := range [][]Schema{sch.OneOf, sch.AnyOf} {
+		for _, alt := range list {
+			if alt.TypeIs(TypeNull) {
+				return nil
+			}
+		}
+	}
+
+	switch exampleType(sch) {
+	case TypeBool:
+		return false
+	case TypeInt:
+		return int64(0)
+	case TypeNum:
+		return 0.0
+	case TypeStr:
+		return ``
+	case TypeArr:
+		return []any{}
+	case TypeObj:
+		return map[string]any{}
+	}
+	return nil
+}
+
+// True if the property is read-only for requests or write-only for responses.
+func (self exampler) skip(prop Schema) bool {
+	tar, _ := self.doc.DerefSchema(prop)
+	if self.Req {
+		return prop.Ronly || tar.Ronly
+	}
+	return prop.Wonly || tar.Wonly
+}
+
+// Value from the first available of "const", "example", "examples", "default", "enum".
+func exampleAnnotated(sch Schema) (any, bool) {
+	switch {
+	case sch.Const != nil:
+		return sch.Const, true
+	case sch.Example != nil:
+		return sch.Example, true
+	case len(sch.Examples) > 0:
+		return sch.Examples[0], true
+	case sch.Default != nil:
+		return sch.Default, true
+	case len(sch.Enum) > 0:
+		return sch.Enum[0], true
+	}
+	return nil, false
+}
+
+// First non-null type, or the type implied by other keywords.
+func exampleType(sch Schema) string {
+	for _, val := range sch.Type {
+		if val != TypeNull {
+			return val
+		}
+	}
+
+	switch {
+	case len(sch.Type) > 0:
+		return TypeNull
+	case len(sch.Props) > 0 || sch.AddProps != nil || len(sch.Requ) > 0:
+		return TypeObj
+	case sch.Items != nil || len(sch.PrefixItems) > 0:
+		return TypeArr
+	}
+	return ``
+}
+
+/*
+Starts with the given default and moves it within the limits of the schema.
+Doesn't attempt to satisfy contradictory limits.
+*/
+func exampleNum(sch Schema, val float64, isInt bool) float64 {
+	if sch.Min != nil && val < *sch.Min {
+		val = *sch.Min
+	}
+	if sch.ExclMin != nil && val <= *sch.ExclMin {
+		val = *sch.ExclMin + 1
+	}
+	if sch.Max != nil && val > *sch.Max {
+		val = *sch.Max
+	}
+	if sch.ExlcMax != nil && val >= *sch.ExlcMax {
+		val = *sch.ExlcMax - 1
+		if sch.ExclMin != nil && val <= *sch.ExclMin {
+			val = (*sch.ExclMin + *sch.ExlcMax) / 2
+		}
+	}
+
+	if sch.MulOf > 0 {
+		val = math.Ceil(val/sch.MulOf) * sch.MulOf
+	}
+	if isInt {
+		val = math.Ceil(val)
+	}
+	return val
+}
+
+func exampleStr(sch Schema) string {
+	val, ok := exampleFormats[sch.Format]
+	if ok {
+		return val
+	}
+	if sch.ContEnc == `base64` {
+		return exampleFormats[FormatByte]
+	}
+
+	val = `string`
+	if uint64(len(val)) < sch.MinLen {
+		val += strings.Repeat(`x`, int(sch.MinLen)-len(val))
+	}
+	if sch.MaxLen > 0 && uint64(len(val)) > sch.MaxLen {
+		val = val[:sch.MaxLen]
+	}
+	return val
+}
+
+var exampleFormats = map[string]string{
+	FormatDateTime:  `2024-01-02T15:04:05Z`,
+	FormatDate:      `2024-01-02`,
+	FormatTime:      `15:04:05Z`,
+	FormatDuration:  `PT1H30M`,
+	FormatUuid:      `3fa85f64-5717-4562-b3fc-2c963f66afa6`,
+	FormatEmail:     `user@example.com`,
+	FormatByte:      `ZXhhbXBsZQ==`,
+	FormatPassword:  `password`,
+	`ipv4`:          `192.0.2.1`,
+	`ipv6`:          `2001:db8::1`,
+	`uri`:           `https://example.com`,
+	`uri-reference`: `/example`,
+	`hostname`:      `example.com`,
+}
+
+/*
+True if the media type at the given JSON Pointer describes a request: a request
+body or a parameter.
+*/
+func exampleIsReq(ptr string) bool {
+	return strings.Contains(ptr, `/requestBody/`) ||
+		strings.HasPrefix(ptr, `/components/requestBodies/`) ||
+		strings.Contains(ptr, `/parameters/`)
+}
+
+func (self *Doc) exampleValid(sch Schema, val any) any {
+	out := ExampleOf(val)
+	err := self.Validate(sch, out)
+	if err != nil {
+		panic(fmt.Errorf(`[oas] example of type %T doesn't match schema: %w`, val, err))
+	}
+	return out
+}
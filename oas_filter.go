@@ -0,0 +1,99 @@
+package oas
+
+import (
+	r "reflect"
+	"strings"
+)
+
+/*
+Predicate used by `oas.Doc.Filter`. For webhooks, `path` is the webhook name.
+`meth` is an uppercase HTTP method such as `http.MethodGet`.
+*/
+type OpFilter func(path, meth string, op Op) bool
+
+/*
+Returns a deep copy of the document containing only operations that satisfy
+the given predicate, in both `.Paths` and `.Webhooks`. Path items left without
+operations are dropped. Path items with `$ref` are kept if the predicate
+accepts any operation of the referenced item, and path items which had no
+operations to begin with, such as items with only parameters, are kept as-is.
+Components and tags which were in use before filtering but are no longer in
+use afterwards are dropped as well, which avoids leaking definitions only used
+by the excluded operations. Components and tags which weren't in use to begin
+with are preserved.
+*/
+func (self Doc) Filter(fun OpFilter) Doc {
+	out := self.Clone()
+	compsPrev := compsReachable(&out)
+	tagsPrev := tagsUsed(&out)
+
+	out.Paths = pathsFilter(&out, out.Paths, fun)
+	out.Webhooks = pathsFilter(&out, out.Webhooks, fun)
+
+	compsNext := compsReachable(&out)
+	tagsNext := tagsUsed(&out)
+
+	for ref := range compsPrev {
+		if !compsNext[ref] {
+			compsDelete(&out.Comps, ref)
+		}
+	}
+
+	tags := out.Tags[:0]
+	for _, tag := range out.Tags {
+		if !tagsPrev[tag.Name] || tagsNext[tag.Name] {
+			tags = append(tags, tag)
+		}
+	}
+	out.Tags = tags
+	return out
+}
+
+// Matches operations which have at least one of the given tags.
+func FilterTags(tags ...string) OpFilter {
+	return func(_, _ string, op Op) bool {
+		for _, val := range op.Tags {
+			for _, tag := range tags {
+				if val == tag {
+					return true
+				}
+			}
+		}
+		return false
+	}
+}
+
+// Matches operations whose path starts with the given prefix.
+func FilterPrefix(prefix string) OpFilter {
+	return func(path, _ string, _ Op) bool {
+		return strings.HasPrefix(path, prefix)
+	}
+}
+
+/*
+Matches operations which have the given extension with the given value,
+compared via `reflect.DeepEqual`. Example: `oas.FilterExt("x-internal", true)`.
+*/
+func FilterExt(key string, val any) OpFilter {
+	return func(_, _ string, op Op) bool {
+		got, ok := op.Exts[key]
+		return ok && r.DeepEqual(got, val)
+	}
+}
+
+// Inverts the given predicate.
+func FilterNot(fun OpFilter) OpFilter {
+	return func(path, meth string, op Op) bool { return !fun(path, meth, op) }
+}
+
+// Matches operations which satisfy all of the given predicates.
+func FilterAnd(funs ...OpFilter) OpFilter {
+	return func(path, meth string, op Op) bool {
+		for _, fun := range funs {
+			if !fun(path, meth, op) {
+				return false
+			}
+		}
+		return true
+	}
+}
@@ -0,0 +1,263 @@
+package oas
+
+import (
+	r "reflect"
+	"sort"
+	"strings"
+)
+
+/*
+Path items with a `$ref` are kept unchanged when the predicate accepts any
+operation of the referenced item, or when the reference can't be resolved.
+Items which had neither operations nor `$ref` to begin with are kept.
+*/
+func pathsFilter(doc *Doc, src Paths, fun OpFilter) Paths {
+	if src == nil {
+		return nil
+	}
+
+	out := make(Paths, len(src))
+	for key, path := range src {
+		found := path.Ref == `` && len(path.Methods()) == 0
+		if path.Ref != `` {
+			found = pathRefMatch(doc, key, path.Ref, fun)
+		}
+		path.AddOps = opsCopy(path.AddOps)
+
+		for _, meth := range path.Methods() {
+			if !fun(key, meth, *path.Op(meth)) {
+				path.setOp(meth, nil)
+				continue
+			}
+			found = true
+		}
+		if found {
+			out[key] = path
+		}
+	}
+	return out
+}
+
+func pathRefMatch(doc *Doc, key, ref string, fun OpFilter) bool {
+	tar, err := ResolveAs[Path](doc, ref)
+	if err != nil {
+		return true
+	}
+	for _, meth := range tar.Methods() {
+		if fun(key, meth, *tar.Op(meth)) {
+			return true
+		}
+	}
+	return false
+}
+
+/*
+Returns the set of components reachable from the parts of the document outside
+of `.Comps`, following references transitively. Components are identified by
+local references in the form `#/components/<kind>/<name>`. Security schemes are
+reachable via security requirements.
+*/
+func compsReachable(doc *Doc) map[string]bool {
+	var queue []string
+	push := func(ref string) { queue = append(queue, ref) }
+
+	root := *doc
+	root.Comps = Comps{}
+	refsEach(&root, push)
+	secReqsEach(&root, push)
+
+	out := map[string]bool{}
+	for len(queue) > 0 {
+		ref := compRef(queue[len(queue)-1])
+		queue = queue[:len(queue)-1]
+		if ref == `` || out[ref] {
+			continue
+		}
+
+		val := compFind(&doc.Comps, ref)
+		if !val.IsValid() {
+			continue
+		}
+
+		out[ref] = true
+		// Map values are not addressable. The walk is read-only, so a shallow copy
+		// suffices.
+		tar := r.New(val.Type())
+		tar.Elem().Set(val)
+		ptr := tar.Interface()
+		refsEach(ptr, push)
+		secReqsEach(ptr, push)
+	}
+	return out
+}
+
+/*
+Returns the sorted references of unreachable components, excluding security
+schemes. See `oas.Doc.Prune`.
+*/
+func compsUnreachable(doc *Doc) (out []string) {
+	reach := doc.Reachable()
+	comps := r.ValueOf(&doc.Comps).Elem()
+
+	for _, field := range compsFields() {
+		if field.name == `securitySchemes` {
+			continue
+		}
+
+		val := comps.FieldByIndex(field.index)
+		for _, key := range val.MapKeys() {
+			ref := ptrAppend(ptrAppend(`#/components`, field.name), key.String())
+			if !reach[ref] {
+				out = append(out, ref)
+			}
+		}
+	}
+
+	sort.Strings(out)
+	return
+}
+
+/*
+Calls the given function for every `$ref` reachable from the given pointer,
+including discriminator mappings. Unlike `refsRewrite`, doesn't modify the
+value.
+*/
+func refsEach(val any, fun func(string)) {
+	_ = Walk(val, func(_ string, val any) error {
+		discr, _ := val.(*Discr)
+		if discr != nil {
+			for _, ref := range discr.Map {
+				fun(ref)
+			}
+		}
+
+		// Item schemas of documents targeting OAS 3.1. See `oas.Doc.StreamMedia`.
+		media, _ := val.(*MediaType)
+		if media != nil {
+			sch, ok := media.Exts[ExtItemSchema].(Schema)
+			if ok {
+				refsEach(&sch, fun)
+			}
+		}
+
+		field := r.ValueOf(val).Elem().FieldByName(`Ref`)
+		if field.Kind() == r.String && field.String() != `` {
+			fun(field.String())
+		}
+		return nil
+	})
+}
+
+/*
+Calls the given function with a security scheme reference for every key of
+every security requirement reachable from the value.
+*/
+func secReqsEach(val any, fun func(string)) {
+	secReqsWalk(val, func(val SecReq) {
+		for key := range val {
+			fun(ptrAppend(`#/components/securitySchemes`, key))
+		}
+	})
+}
+
+// Calls the given function for every security requirement.
+func secReqsWalk(val any, fun func(SecReq)) {
+	_ = Walk(val, func(_ string, val any) error {
+		var reqs []SecReq
+		switch val := val.(type) {
+		case *Doc:
+			reqs = val.Security
+		case *Op:
+			reqs = val.Sec
+		}
+		for _, req := range reqs {
+			fun(req)
+		}
+		return nil
+	})
+}
+
+/*
+Truncates a local reference to a component to the component itself, for
+example `#/components/schemas/One/properties/two` to `#/components/schemas/One`.
+Returns "" for references which don't point into components.
+*/
+func compRef(ref string) string {
+	rest, ok := unprefix(ref, `#/components/`)
+	if !ok {
+		return ``
+	}
+
+	ind := strings.IndexByte(rest, '/')
+	if ind < 0 {
+		return ``
+	}
+
+	end := strings.IndexByte(rest[ind+1:], '/')
+	if end >= 0 {
+		rest = rest[:ind+1+end]
+	}
+	return `#/components/` + rest
+}
+
+// Splits a component reference into the map of its kind and the unescaped key.
+func compSplit(comps *Comps, ref string) (r.Value, r.Value) {
+	rest, ok := unprefix(ref, `#/components/`)
+	if !ok {
+		return r.Value{}, r.Value{}
+	}
+
+	kind, name, ok := strings.Cut(rest, `/`)
+	if !ok {
+		return r.Value{}, r.Value{}
+	}
+
+	for _, field := range compsFields() {
+		if field.name == kind {
+			return r.ValueOf(comps).Elem().FieldByIndex(field.index), r.ValueOf(ptrUnescaper.Replace(name))
+		}
+	}
+	return r.Value{}, r.Value{}
+}
+
+func compFind(comps *Comps, ref string) r.Value {
+	val, key := compSplit(comps, ref)
+	if !val.IsValid() {
+		return val
+	}
+	return val.MapIndex(key)
+}
+
+func compsDelete(comps *Comps, ref string) {
+	val, key := compSplit(comps, ref)
+	if val.IsValid() && !val.IsNil() {
+		val.SetMapIndex(key, r.Value{})
+	}
+}
+
+// Set of tags used by operations anywhere in the document.
+func tagsUsed(doc *Doc) map[string]bool {
+	out := map[string]bool{}
+	_ = Walk(doc, func(_ string, val any) error {
+		op, _ := val.(*Op)
+		if op != nil {
+			for _, tag := range op.Tags {
+				out[tag] = true
+			}
+		}
+		return nil
+	})
+	return out
+}
+
+// Shallow copy, to avoid deleting operations from the source document.
+func opsCopy(src Ops) Ops {
+	if src == nil {
+		return nil
+	}
+	out := make(Ops, len(src))
+	for key, val := range src {
+		out[key] = val
+	}
+	return out
+}
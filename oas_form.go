@@ -0,0 +1,56 @@
+package oas
+
+const (
+	/**
+	Struct tags used by `oas.Doc.FormBody` and `oas.Doc.MultipartBody`. The
+	"form" tag specifies the field name, like the "json" tag; "-" skips the
+	field. The "contentType" tag specifies the content type of a multipart part,
+	or a comma-separated list of allowed types, for example
+	`contentType:"image/png,image/jpeg"`.
+	*/
+	TagForm    = `form`
+	TagConType = `contentType`
+)
+
+/*
+Returns `oas.Body` describing an "application/x-www-form-urlencoded" request
+with fields of the given struct type, after registering the schemas of field
+types in the document. The input is used only as a type carrier. Field names
+come from `form` tags, defaulting to Go field names; embedded structs without
+tags are flattened. The object schema is inline, rather than a component, since
+the field names typically differ from the JSON representation of the same
+type. Panics if the type is not a struct, or if a field is a file, which is
+only supported by `oas.Doc.MultipartBody`.
+*/
+func (self *Doc) FormBody(typ any) Body {
+	sch, _ := self.formSchema(typ, false)
+	return Body{Cont: MediaTypes{ConTypeForm: {Schema: sch}}}
+}
+
+/*
+Returns `oas.Body` describing a "multipart/form-data" request with fields of
+the given struct type, like `oas.Doc.FormBody`. File fields, of type
+`*multipart.FileHeader`, `[]*multipart.FileHeader` or an interface
+implementing `io.Reader`, are described as binary strings with
+"contentMediaType". `oas.MediaType.Encoding` specifies the content types of
+file parts, from `contentType` tags, defaulting to
+"application/octet-stream". The given encodings are merged into the
+generated ones, and may specify additional properties such as part headers:
+
+	doc.MultipartBody(Upload{}, oas.Encodings{
+		`avatar`: {Head: oas.Heads{`X-Rate-Limit`: {Schema: doc.Sch(0).Opt()}}},
+	})
+*/
+func (self *Doc) MultipartBody(typ any, enc Encodings) Body {
+	sch, gen := self.formSchema(typ, true)
+
+	for key, val := range enc {
+		prev, ok := gen[key]
+		if ok && val.ConType == `` {
+			val.ConType = prev.ConType
+		}
+		gen.Init()[key] = val
+	}
+
+	return Body{Cont: MediaTypes{ConTypeMultipart: {Schema: sch, Encoding: gen}}}
+}
@@ -0,0 +1,100 @@
+package oas
+
+import (
+	"fmt"
+	"io"
+	"mime/multipart"
+	r "reflect"
+	"strings"
+)
+
+var (
+	typeFileHeader = r.TypeOf((*multipart.FileHeader)(nil))
+	typeReader     = r.TypeOf((*io.Reader)(nil)).Elem()
+)
+
+/*
+Inline object schema for a form struct, and encodings of its file fields.
+Files are allowed only in multipart forms.
+*/
+func (self *Doc) formSchema(val any, multi bool) (Schema, Encodings) {
+	typ := typeDeref(r.TypeOf(val))
+	if typ == nil || typ.Kind() != r.Struct {
+		panic(fmt.Errorf(`[oas] expected form struct type, got %v`, typ))
+	}
+
+	sch := Schema{Type: []string{TypeObj}}
+	var enc Encodings
+	self.formProps(&sch, &enc, typ, multi)
+	return sch, enc
+}
+
+func (self *Doc) formProps(sch *Schema, enc *Encodings, typ r.Type, multi bool) {
+	for ind := range iter(typ.NumField()) {
+		field := typ.Field(ind)
+		tag := field.Tag.Get(TagForm)
+
+		if !isPublic(field.PkgPath) || tag == `-` {
+			continue
+		}
+
+		name := tagIdent(tag)
+		if name == `` && field.Anonymous {
+			inner := typeDeref(field.Type)
+			if inner.Kind() == r.Struct && inner != typeFileHeader.Elem() {
+				self.formProps(sch, enc, inner, multi)
+				continue
+			}
+		}
+		if name == `` {
+			name = field.Name
+		}
+
+		conType := field.Tag.Get(TagConType)
+		prop, isFile := formFile(field.Type, conType)
+		if isFile {
+			if !multi {
+				panic(fmt.Errorf(`[oas] unable to describe file field %q of %v in a form without multipart encoding`, field.Name, typ))
+			}
+			if conType == `` {
+				conType = ConTypeBin
+			}
+			enc.Init()[name] = Encoding{ConType: conType}
+		} else {
+			if isTypeSkippable(field.Type) {
+				continue
+			}
+			prop = self.TypeSchema(field.Type)
+			if conType != `` && multi {
+				enc.Init()[name] = Encoding{ConType: conType}
+			}
+		}
+
+		sch.Props.Init()[name] = prop
+	}
+}
+
+/*
+Schema of a file field: a binary string as recommended by OAS 3.1, or an
+array of such strings.
+*/
+func formFile(typ r.Type, conType string) (Schema, bool) {
+	if typ.Kind() == r.Slice && formIsFile(typ.Elem()) {
+		item, _ := formFile(typ.Elem(), conType)
+		return Schema{Type: []string{TypeArr}, Items: &item}, true
+	}
+	if !formIsFile(typ) {
+		return Schema{}, false
+	}
+
+	out := Schema{Type: []string{TypeStr}, ContMedia: ConTypeBin}
+	if conType != `` && !strings.Contains(conType, `,`) {
+		out.ContMedia = conType
+	}
+	return out, true
+}
+
+func formIsFile(typ r.Type) bool {
+	return typ == typeFileHeader ||
+		(typ.Kind() == r.Interface && typ.Implements(typeReader))
+}
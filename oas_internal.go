@@ -55,6 +55,13 @@ func errSchemaRedundant(name string) error {
 	return fmt.Errorf(`[oas] redundant schema %q`, name)
 }
 
+func errYamlUnsupported(typ r.Type) error {
+	return fmt.Errorf(
+		`[oas] can't encode type %q of kind %q as YAML`,
+		typ, typ.Kind(),
+	)
+}
+
 func validKeyFor(mapType, keyType r.Type, keySch Schema) {
 	if !keySch.TypeIs(TypeStr) {
 		panic(fmt.Errorf(
@@ -359,6 +366,10 @@ func jsonName(field r.StructField) string {
 	return tagIdent(field.Tag.Get(`json`))
 }
 
+func isJsonSkipped(field r.StructField) bool {
+	return field.Tag.Get(`json`) == `-`
+}
+
 func tagIdent(tag string) string {
 	index := strings.IndexRune(tag, ',')
 	if index >= 0 {
@@ -412,9 +423,140 @@ func toText(val encoding.TextMarshaler) (_ []byte, err error) {
 	return val.MarshalText()
 }
 
+/*
+Used for "omitempty" by our JSON and YAML encoders. Mimics the behavior of
+popular YAML libraries, which, unlike "encoding/json", also omit zero structs.
+*/
+func isValueEmpty(val r.Value) bool {
+	switch val.Kind() {
+	case r.Array, r.Map, r.Slice, r.String:
+		return val.Len() == 0
+	case r.Interface, r.Ptr:
+		return val.IsNil()
+	default:
+		return val.IsZero()
+	}
+}
+
+// Appends a reference token to a JSON Pointer, escaping it as required.
+func ptrAppend(ptr, key string) string {
+	return ptr + `/` + ptrEscaper.Replace(key)
+}
+
+var ptrEscaper = strings.NewReplacer(`~`, `~0`, `/`, `~1`)
+
+var ptrUnescaper = strings.NewReplacer(`~1`, `/`, `~0`, `~`)
+
+// Last reference token of a JSON Pointer, unescaped.
+func ptrLast(ptr string) string {
+	return ptrUnescaper.Replace(ptr[strings.LastIndexByte(ptr, '/')+1:])
+}
+
 func unprefix(base, prefix string) (string, bool) {
 	if strings.HasPrefix(base, prefix) {
 		return base[len(prefix):], true
 	}
 	return base, false
 }
+
+// Deep copy of an arbitrary value. Foreign structs are copied shallowly.
+func cloneValue(src r.Value) r.Value {
+	out := r.New(src.Type()).Elem()
+	cloneInto(out, src)
+	return out
+}
+
+func cloneInto(tar, src r.Value) {
+	switch src.Kind() {
+	case r.Ptr:
+		if !src.IsNil() {
+			val := r.New(src.Type().Elem())
+			cloneInto(val.Elem(), src.Elem())
+			tar.Set(val)
+		}
+
+	case r.Interface:
+		if !src.IsNil() {
+			tar.Set(cloneValue(src.Elem()))
+		}
+
+	case r.Struct:
+		if !isOwnType(src.Type()) {
+			tar.Set(src)
+			return
+		}
+		for ind := range iter(src.NumField()) {
+			cloneInto(tar.Field(ind), src.Field(ind))
+		}
+
+	case r.Slice:
+		if !src.IsNil() {
+			val := r.MakeSlice(src.Type(), src.Len(), src.Len())
+			for ind := range iter(src.Len()) {
+				cloneInto(val.Index(ind), src.Index(ind))
+			}
+			tar.Set(val)
+		}
+
+	case r.Array:
+		for ind := range iter(src.Len()) {
+			cloneInto(tar.Index(ind), src.Index(ind))
+		}
+
+	case r.Map:
+		if !src.IsNil() {
+			val := r.MakeMapWithSize(src.Type(), src.Len())
+			iter := src.MapRange()
+			for iter.Next() {
+				val.SetMapIndex(iter.Key(), cloneValue(iter.Value()))
+			}
+			tar.Set(val)
+		}
+
+	default:
+		tar.Set(src)
+	}
+}
+
+// Whether values of this type may contain this package's types.
+func jsonHasOwnStrict(typ r.Type) bool {
+	switch typ.Kind() {
+	case r.Ptr, r.Slice, r.Array, r.Map:
+		return jsonHasOwnStrict(typ.Elem())
+	case r.Struct:
+		return isOwnType(typ)
+	default:
+		return false
+	}
+}
+
+/*
+Replaces every `$ref` reachable from the given pointer, including discriminator
+mappings, with the output of the given function.
+*/
+func refsRewrite(val any, fun func(string) string) {
+	_ = Walk(val, func(_ string, val any) error {
+		discr, _ := val.(*Discr)
+		if discr != nil {
+			for key, ref := range discr.Map {
+				discr.Map[key] = fun(ref)
+			}
+		}
+
+		// Item schemas of documents targeting OAS 3.1. See `oas.Doc.StreamMedia`.
+		media, _ := val.(*MediaType)
+		if media != nil {
+			sch, ok := media.Exts[ExtItemSchema].(Schema)
+			if ok {
+				refsRewrite(&sch, fun)
+				media.Exts[ExtItemSchema] = sch
+			}
+		}
+
+		field := r.ValueOf(val).Elem().FieldByName(`Ref`)
+		if field.Kind() == r.String && field.String() != `` {
+			field.SetString(fun(field.String()))
+		}
+		return nil
+	})
+}
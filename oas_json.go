@@ -0,0 +1,117 @@
+package oas
+
+import "fmt"
+
+/*
+Decodes JSON into the given output, which must be a non-nil pointer, typically
+to `oas.Doc` or another type from this package. Unlike "encoding/json", this
+understands the actual shapes used by the spec:
+
+  - Specification extensions ("x-" properties) are collected into `.Exts`.
+
+  - Boolean schemas are decoded into `oas.Schema` with `.Bool` set.
+
+  - Schema `type` may be either a string or a list.
+
+  - Unrecognized schema keywords are preserved in `oas.Schema.Exts`.
+
+  - Numbers in "any"-typed fields such as `.Example` and `.Enum` are decoded
+    as `json.Number`, preserving precision.
+
+  - Explicit null in "any"-typed fields such as `.Default` is decoded as
+    `oas.Null{}`, distinguishing it from an absent field.
+
+When decoding an `oas.Doc` with an `openapi` version "3.0.*", the 3.0-specific
+schema keywords `nullable` and boolean `exclusiveMinimum` and
+`exclusiveMaximum` are converted to their 3.1 equivalents; `nullable` also
+adds null to `enum`, if any. `nullable` without `type` has no effect in 3.0
+and is dropped. The `openapi` version itself is left unchanged.
+
+Unrecognized non-extension properties in OAS objects are rejected.
+Extensions directly inside the "Paths" and "Responses" objects are collected
+into `oas.Doc.PathsExts` and `oas.Op.RespsExts`, because `oas.Paths` and
+`oas.Resps` are plain maps, and are encoded back inside those objects. Errors
+are of type `oas.DecodeErr`, indicating the location of the problem as a JSON
+Pointer.
+*/
+func DecodeJson(src []byte, out any) error { return jsonUnmarshal(src, out) }
+
+/*
+Error type returned by `oas.DecodeJson` and by the `.UnmarshalJSON` methods of
+this package's types. `.Ptr` is a JSON Pointer to the problematic location in
+the input document, empty for the document root.
+*/
+type DecodeErr struct {
+	Ptr   string
+	Cause error
+}
+
+// Implement `error`.
+func (self DecodeErr) Error() string {
+	return fmt.Sprintf(`[oas] failed to decode JSON at %q: %v`, self.Ptr, self.Cause)
+}
+
+// Implement a hidden interface in "errors".
+func (self DecodeErr) Unwrap() error { return self.Cause }
+
+/*
+The JSON methods below use this package's reflection-based codec, see
+`oas.DecodeJson`. Encoding differs from "encoding/json" by encoding `.Exts`
+inline, encoding boolean schemas as booleans, and by omitting zero-valued
+structs marked with "omitempty".
+*/
+
+func (self Doc) MarshalJSON() ([]byte, error)          { return jsonMarshal(self) }
+func (self *Doc) UnmarshalJSON(src []byte) error       { return jsonUnmarshal(src, self) }
+func (self Ref) MarshalJSON() ([]byte, error)          { return jsonMarshal(self) }
+func (self *Ref) UnmarshalJSON(src []byte) error       { return jsonUnmarshal(src, self) }
+func (self Info) MarshalJSON() ([]byte, error)         { return jsonMarshal(self) }
+func (self *Info) UnmarshalJSON(src []byte) error      { return jsonUnmarshal(src, self) }
+func (self Contact) MarshalJSON() ([]byte, error)      { return jsonMarshal(self) }
+func (self *Contact) UnmarshalJSON(src []byte) error   { return jsonUnmarshal(src, self) }
+func (self License) MarshalJSON() ([]byte, error)      { return jsonMarshal(self) }
+func (self *License) UnmarshalJSON(src []byte) error   { return jsonUnmarshal(src, self) }
+func (self Server) MarshalJSON() ([]byte, error)       { return jsonMarshal(self) }
+func (self *Server) UnmarshalJSON(src []byte) error    { return jsonUnmarshal(src, self) }
+func (self Var) MarshalJSON() ([]byte, error)          { return jsonMarshal(self) }
+func (self *Var) UnmarshalJSON(src []byte) error       { return jsonUnmarshal(src, self) }
+func (self Comps) MarshalJSON() ([]byte, error)        { return jsonMarshal(self) }
+func (self *Comps) UnmarshalJSON(src []byte) error     { return jsonUnmarshal(src, self) }
+func (self Path) MarshalJSON() ([]byte, error)         { return jsonMarshal(self) }
+func (self *Path) UnmarshalJSON(src []byte) error      { return jsonUnmarshal(src, self) }
+func (self Op) MarshalJSON() ([]byte, error)           { return jsonMarshal(self) }
+func (self *Op) UnmarshalJSON(src []byte) error        { return jsonUnmarshal(src, self) }
+func (self ExtDoc) MarshalJSON() ([]byte, error)       { return jsonMarshal(self) }
+func (self *ExtDoc) UnmarshalJSON(src []byte) error    { return jsonUnmarshal(src, self) }
+func (self Param) MarshalJSON() ([]byte, error)        { return jsonMarshal(self) }
+func (self *Param) UnmarshalJSON(src []byte) error     { return jsonUnmarshal(src, self) }
+func (self Body) MarshalJSON() ([]byte, error)         { return jsonMarshal(self) }
+func (self *Body) UnmarshalJSON(src []byte) error      { return jsonUnmarshal(src, self) }
+func (self MediaType) MarshalJSON() ([]byte, error)    { return jsonMarshal(self) }
+func (self *MediaType) UnmarshalJSON(src []byte) error { return jsonUnmarshal(src, self) }
+func (self Encoding) MarshalJSON() ([]byte, error)     { return jsonMarshal(self) }
+func (self *Encoding) UnmarshalJSON(src []byte) error  { return jsonUnmarshal(src, self) }
+func (self Resp) MarshalJSON() ([]byte, error)         { return jsonMarshal(self) }
+func (self *Resp) UnmarshalJSON(src []byte) error      { return jsonUnmarshal(src, self) }
+func (self Callback) MarshalJSON() ([]byte, error)     { return jsonMarshal(self) }
+func (self *Callback) UnmarshalJSON(src []byte) error  { return jsonUnmarshal(src, self) }
+func (self Example) MarshalJSON() ([]byte, error)      { return jsonMarshal(self) }
+func (self *Example) UnmarshalJSON(src []byte) error   { return jsonUnmarshal(src, self) }
+func (self Link) MarshalJSON() ([]byte, error)         { return jsonMarshal(self) }
+func (self *Link) UnmarshalJSON(src []byte) error      { return jsonUnmarshal(src, self) }
+func (self Head) MarshalJSON() ([]byte, error)         { return jsonMarshal(self) }
+func (self *Head) UnmarshalJSON(src []byte) error      { return jsonUnmarshal(src, self) }
+func (self Tag) MarshalJSON() ([]byte, error)          { return jsonMarshal(self) }
+func (self *Tag) UnmarshalJSON(src []byte) error       { return jsonUnmarshal(src, self) }
+func (self Discr) MarshalJSON() ([]byte, error)        { return jsonMarshal(self) }
+func (self *Discr) UnmarshalJSON(src []byte) error     { return jsonUnmarshal(src, self) }
+func (self Xml) MarshalJSON() ([]byte, error)          { return jsonMarshal(self) }
+func (self *Xml) UnmarshalJSON(src []byte) error       { return jsonUnmarshal(src, self) }
+func (self SecScheme) MarshalJSON() ([]byte, error)    { return jsonMarshal(self) }
+func (self *SecScheme) UnmarshalJSON(src []byte) error { return jsonUnmarshal(src, self) }
+func (self Flows) MarshalJSON() ([]byte, error)        { return jsonMarshal(self) }
+func (self *Flows) UnmarshalJSON(src []byte) error     { return jsonUnmarshal(src, self) }
+func (self Flow) MarshalJSON() ([]byte, error)         { return jsonMarshal(self) }
+func (self *Flow) UnmarshalJSON(src []byte) error      { return jsonUnmarshal(src, self) }
+func (self Schema) MarshalJSON() ([]byte, error)       { return jsonMarshal(self) }
+func (self *Schema) UnmarshalJSON(src []byte) error    { return jsonUnmarshal(src, self) }
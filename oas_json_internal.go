@@ -0,0 +1,803 @@
+package oas
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	r "reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+/*
+This file contains the internals of the JSON codec used by this package's
+types. Our types are walked by reflection; everything else is delegated to
+"encoding/json". Struct fields are described by `json` tags, except for inline
+maps such as `.Exts`, which are marked with `yaml:",inline"`, since the `json`
+tag has no such option.
+*/
+
+var (
+	ownPkgPath           = r.TypeOf(Doc{}).PkgPath()
+	ifaceJsonUnmarshaler = r.TypeOf((*json.Unmarshaler)(nil)).Elem()
+	typeSchema           = r.TypeOf(Schema{})
+	typeMediaType        = r.TypeOf(MediaType{})
+	schemaBoolIndex      = fieldIndex(typeSchema, `Bool`)
+)
+
+func fieldIndex(typ r.Type, name string) []int {
+	field, ok := typ.FieldByName(name)
+	if !ok {
+		panic(fmt.Errorf(`[oas] missing field %q in type %q`, name, typ))
+	}
+	return field.Index
+}
+
+/*
+True for struct types handled by our codec rather than "encoding/json". All
+such types implement `json.Unmarshaler` by delegating to the codec. Checking
+the package path alone is not enough, because test types live in this
+package too.
+*/
+func isOwnType(typ r.Type) bool {
+	return typ != nil &&
+		typ.Kind() == r.Struct &&
+		typ.PkgPath() == ownPkgPath &&
+		r.PointerTo(typ).Implements(ifaceJsonUnmarshaler)
+}
+
+// Returns the boolean of a boolean schema, or nil.
+func schemaBoolOf(val r.Value) *bool {
+	return val.FieldByIndex(schemaBoolIndex).Interface().(*bool)
+}
+
+type jsonField struct {
+	index     []int
+	name      string
+	omitEmpty bool
+	inline    bool
+	exts      bool
+	side      []int
+}
+
+var jsonFieldCache sync.Map
+
+func jsonFieldsOf(typ r.Type) []jsonField {
+	val, ok := jsonFieldCache.Load(typ)
+	if ok {
+		return val.([]jsonField)
+	}
+	out := jsonFieldsAppend(nil, typ, nil)
+	jsonFieldCache.Store(typ, out)
+	return out
+}
+
+func jsonFieldsAppend(out []jsonField, typ r.Type, index []int) []jsonField {
+	for ind := range iter(typ.NumField()) {
+		field := typ.Field(ind)
+		path := append(index[:len(index):len(index)], ind)
+		opts := strings.Split(field.Tag.Get(`json`), `,`)
+		name := opts[0]
+
+		if field.Anonymous && name == `` && field.Type.Kind() == r.Struct {
+			out = jsonFieldsAppend(out, field.Type, path)
+			continue
+		}
+
+		if !isPublic(field.PkgPath) {
+			continue
+		}
+
+		if name == `-` {
+			if yamlTagOf(field).inline && field.Type.Kind() == r.Map {
+				out = append(out, jsonField{
+					index:  path,
+					inline: true,
+					exts:   field.Name == `Exts`,
+				})
+			}
+			continue
+		}
+
+		if name == `` {
+			name = field.Name
+		}
+
+		var side []int
+		sideIndex := sideExtsIndex(typ, field)
+		if sideIndex != nil {
+			side = append(index[:len(index):len(index)], sideIndex...)
+		}
+
+		out = append(out, jsonField{
+			index:     path,
+			name:      name,
+			omitEmpty: stringsContain(opts[1:], `omitempty`),
+			side:      side,
+		})
+	}
+	return out
+}
+
+/*
+Index of the field holding the extensions of the map in the given field, or
+nil. By convention, such fields are named after the map field with the suffix
+"Exts", like `oas.Doc.PathsExts`, and their entries are encoded inside the map.
+This allows extensions in objects such as "Paths" and "Responses", which we
+represent as plain maps.
+*/
+func sideExtsIndex(typ r.Type, field r.StructField) []int {
+	if field.Type.Kind() != r.Map {
+		return nil
+	}
+	side, ok := typ.FieldByName(field.Name + `Exts`)
+	if !ok || side.Type != r.TypeOf(Anys(nil)) {
+		return nil
+	}
+	return side.Index
+}
+
+func jsonMarshal(val any) ([]byte, error) {
+	var enc jsonEncoder
+	err := enc.any(r.ValueOf(val))
+	return enc.Bytes(), err
+}
+
+type jsonEncoder struct{ bytes.Buffer }
+
+func (self *jsonEncoder) any(val r.Value) error {
+	if !val.IsValid() {
+		self.WriteString(`null`)
+		return nil
+	}
+
+	typ := val.Type()
+	if !jsonHasOwn(typ) {
+		return self.foreign(val)
+	}
+
+	switch typ.Kind() {
+	case r.Interface, r.Ptr:
+		if val.IsNil() {
+			self.WriteString(`null`)
+			return nil
+		}
+		return self.any(val.Elem())
+
+	case r.Struct:
+		return self.object(val)
+
+	case r.Map:
+		if val.IsNil() {
+			self.WriteString(`null`)
+			return nil
+		}
+		self.WriteByte('{')
+		err := self.entries(val, true)
+		self.WriteByte('}')
+		return err
+
+	case r.Slice:
+		if val.IsNil() {
+			self.WriteString(`null`)
+			return nil
+		}
+		return self.array(val)
+
+	case r.Array:
+		return self.array(val)
+
+	default:
+		return self.foreign(val)
+	}
+}
+
+func (self *jsonEncoder) foreign(val r.Value) error {
+	chunk, err := json.Marshal(val.Interface())
+	if err != nil {
+		return err
+	}
+	self.Write(chunk)
+	return nil
+}
+
+func (self *jsonEncoder) object(val r.Value) error {
+	typ := val.Type()
+
+	if typ == typeSchema {
+		ptr := schemaBoolOf(val)
+		if ptr != nil {
+			self.WriteString(strconv.FormatBool(*ptr))
+			return nil
+		}
+	}
+
+	self.WriteByte('{')
+	first := true
+
+	for _, field := range jsonFieldsOf(typ) {
+		fieldVal := val.FieldByIndex(field.index)
+
+		if field.inline {
+			if fieldVal.Len() > 0 {
+				err := self.entries(fieldVal, first)
+				if err != nil {
+					return err
+				}
+				first = false
+			}
+			continue
+		}
+
+		var side r.Value
+		if field.side != nil {
+			side = val.FieldByIndex(field.side)
+		}
+		hasSide := side.IsValid() && side.Len() > 0
+
+		if field.omitEmpty && isValueEmpty(fieldVal) && !hasSide {
+			continue
+		}
+
+		if !first {
+			self.WriteByte(',')
+		}
+		first = false
+
+		self.string(field.name)
+		self.WriteByte(':')
+
+		var err error
+		if hasSide {
+			err = self.sided(fieldVal, side)
+		} else if fieldVal.Kind() == r.Map && fieldVal.IsNil() {
+			// Fields without "omitempty" are required objects, like
+			// `oas.Flow.Scopes`, where null is invalid.
+			self.WriteString(`{}`)
+		} else {
+			err = self.any(fieldVal)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	self.WriteByte('}')
+	return nil
+}
+
+// Writes a map followed by the extensions from its side map, see `sideExtsIndex`.
+func (self *jsonEncoder) sided(val, side r.Value) error {
+	self.WriteByte('{')
+	err := self.entries(val, true)
+	if err == nil {
+		err = self.entries(side, val.Len() == 0)
+	}
+	self.WriteByte('}')
+	return err
+}
+
+// Writes map entries with sorted keys, without the enclosing braces.
+func (self *jsonEncoder) entries(val r.Value, first bool) error {
+	for _, key := range sortedMapKeys(val) {
+		if !first {
+			self.WriteByte(',')
+		}
+		first = false
+
+		self.string(key.String())
+		self.WriteByte(':')
+		err := self.any(val.MapIndex(key))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (self *jsonEncoder) array(val r.Value) error {
+	self.WriteByte('[')
+	for ind := range iter(val.Len()) {
+		if ind > 0 {
+			self.WriteByte(',')
+		}
+		err := self.any(val.Index(ind))
+		if err != nil {
+			return err
+		}
+	}
+	self.WriteByte(']')
+	return nil
+}
+
+func (self *jsonEncoder) string(val string) {
+	chunk, _ := json.Marshal(val)
+	self.Write(chunk)
+}
+
+func sortedMapKeys(val r.Value) []r.Value {
+	keys := val.MapKeys()
+	sort.Slice(keys, func(one, two int) bool {
+		return keys[one].String() < keys[two].String()
+	})
+	return keys
+}
+
+var jsonHasOwnCache sync.Map
+
+/*
+True if values of the given type may contain our own types, which must be
+walked by our encoder. Interfaces are assumed to possibly contain them.
+*/
+func jsonHasOwn(typ r.Type) bool {
+	val, ok := jsonHasOwnCache.Load(typ)
+	if ok {
+		return val.(bool)
+	}
+
+	// Placeholder for recursive types.
+	jsonHasOwnCache.Store(typ, false)
+
+	var out bool
+	switch typ.Kind() {
+	case r.Interface:
+		out = true
+	case r.Ptr, r.Slice, r.Array, r.Map:
+		out = jsonHasOwn(typ.Elem())
+	case r.Struct:
+		out = isOwnType(typ)
+	}
+
+	jsonHasOwnCache.Store(typ, out)
+	return out
+}
+
+func jsonUnmarshal(src []byte, out any) error {
+	tar := r.ValueOf(out)
+	if tar.Kind() != r.Ptr || tar.IsNil() {
+		return fmt.Errorf(`[oas] can't decode JSON into non-pointer or nil %T`, out)
+	}
+
+	tree, err := jsonParse(src)
+	if err != nil {
+		return DecodeErr{Cause: err}
+	}
+
+	var dec jsonDecoder
+	if tar.Type().Elem() == r.TypeOf(Doc{}) {
+		err := dec.version(tree)
+		if err != nil {
+			return DecodeErr{Cause: err}
+		}
+	}
+	return dec.any(tree, tar.Elem(), ``)
+}
+
+// Parses arbitrary JSON, preserving the exact representation of numbers.
+func jsonParse(src []byte) (out any, err error) {
+	dec := json.NewDecoder(bytes.NewReader(src))
+	dec.UseNumber()
+
+	err = dec.Decode(&out)
+	if err != nil {
+		return
+	}
+
+	_, err = dec.Token()
+	if err == io.EOF {
+		return out, nil
+	}
+	if err == nil {
+		err = errors.New(`unexpected data after top-level value`)
+	}
+	return
+}
+
+type jsonDecoder struct{ v30 bool }
+
+func (self *jsonDecoder) version(tree any) error {
+	obj, _ := tree.(map[string]any)
+
+	if obj[`swagger`] != nil {
+		return errors.New(`OpenAPI 2.0 (Swagger) documents are not supported`)
+	}
+
+	ver, _ := obj[`openapi`].(string)
+	self.v30 = strings.HasPrefix(ver, `3.0.`)
+	return nil
+}
+
+func (self *jsonDecoder) any(src any, tar r.Value, ptr string) error {
+	typ := tar.Type()
+
+	if src == nil {
+		tar.Set(r.Zero(typ))
+		return nil
+	}
+
+	switch typ.Kind() {
+	case r.Interface:
+		if typ.NumMethod() > 0 {
+			return errJsonUnsupported(ptr, typ)
+		}
+		tar.Set(r.ValueOf(src))
+		return nil
+
+	case r.Ptr:
+		elem := r.New(typ.Elem())
+		err := self.any(src, elem.Elem(), ptr)
+		if err != nil {
+			return err
+		}
+		tar.Set(elem)
+		return nil
+
+	case r.Struct:
+		if isOwnType(typ) {
+			return self.object(src, tar, ptr)
+		}
+		return self.foreign(src, tar, ptr)
+
+	case r.Map:
+		return self.mapping(src, tar, r.Value{}, ptr)
+
+	case r.Slice:
+		return self.slice(src, tar, ptr)
+
+	case r.String:
+		val, ok := src.(string)
+		if !ok {
+			return errJsonType(ptr, `string`, src)
+		}
+		tar.SetString(val)
+		return nil
+
+	case r.Bool:
+		val, ok := src.(bool)
+		if !ok {
+			return errJsonType(ptr, `boolean`, src)
+		}
+		tar.SetBool(val)
+		return nil
+
+	case r.Int8, r.Int16, r.Int32, r.Int64, r.Int:
+		val, err := jsonInt(src, ptr)
+		if err != nil {
+			return err
+		}
+		if tar.OverflowInt(val) {
+			return DecodeErr{ptr, fmt.Errorf(`number %v overflows %v`, val, typ)}
+		}
+		tar.SetInt(val)
+		return nil
+
+	case r.Uint8, r.Uint16, r.Uint32, r.Uint64, r.Uint:
+		val, err := jsonInt(src, ptr)
+		if err != nil {
+			return err
+		}
+		if val < 0 || tar.OverflowUint(uint64(val)) {
+			return DecodeErr{ptr, fmt.Errorf(`number %v overflows %v`, val, typ)}
+		}
+		tar.SetUint(uint64(val))
+		return nil
+
+	case r.Float32, r.Float64:
+		num, ok := src.(json.Number)
+		if !ok {
+			return errJsonType(ptr, `number`, src)
+		}
+		val, err := num.Float64()
+		if err != nil {
+			return DecodeErr{ptr, err}
+		}
+		tar.SetFloat(val)
+		return nil
+
+	default:
+		return errJsonUnsupported(ptr, typ)
+	}
+}
+
+// Accepts integral numbers, including those written with a fraction, like "1.0".
+func jsonInt(src any, ptr string) (int64, error) {
+	num, ok := src.(json.Number)
+	if !ok {
+		return 0, errJsonType(ptr, `integer`, src)
+	}
+
+	val, err := num.Int64()
+	if err == nil {
+		return val, nil
+	}
+
+	flo, err := num.Float64()
+	if err != nil || flo != math.Trunc(flo) {
+		return 0, errJsonType(ptr, `integer`, src)
+	}
+	return int64(flo), nil
+}
+
+func (self *jsonDecoder) foreign(src any, tar r.Value, ptr string) error {
+	chunk, err := json.Marshal(src)
+	if err == nil {
+		err = json.Unmarshal(chunk, tar.Addr().Interface())
+	}
+	if err != nil {
+		return DecodeErr{ptr, err}
+	}
+	return nil
+}
+
+/*
+Decodes an object into a map. If the side map is valid, extensions go there,
+see `sideExtsIndex`.
+*/
+func (self *jsonDecoder) mapping(src any, tar, side r.Value, ptr string) error {
+	obj, ok := src.(map[string]any)
+	if !ok {
+		return errJsonType(ptr, `object`, src)
+	}
+
+	typ := tar.Type()
+	if tar.IsNil() {
+		tar.Set(r.MakeMapWithSize(typ, len(obj)))
+	}
+
+	for _, key := range sortedKeys(obj) {
+		out := tar
+		if side.IsValid() && strings.HasPrefix(key, `x-`) {
+			out = side
+		}
+
+		err := self.entry(key, obj[key], out, ptrAppend(ptr, key))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (self *jsonDecoder) entry(key string, src any, tar r.Value, ptr string) error {
+	typ := tar.Type()
+	if tar.IsNil() {
+		tar.Set(r.MakeMap(typ))
+	}
+
+	elem := r.New(typ.Elem()).Elem()
+	err := self.any(src, elem, ptr)
+	if err != nil {
+		return err
+	}
+
+	tar.SetMapIndex(r.ValueOf(key).Convert(typ.Key()), elem)
+	return nil
+}
+
+func (self *jsonDecoder) slice(src any, tar r.Value, ptr string) error {
+	list, ok := src.([]any)
+	if !ok {
+		return errJsonType(ptr, `array`, src)
+	}
+
+	out := r.MakeSlice(tar.Type(), len(list), len(list))
+	for ind, val := range list {
+		err := self.any(val, out.Index(ind), ptrAppend(ptr, strconv.Itoa(ind)))
+		if err != nil {
+			return err
+		}
+	}
+	tar.Set(out)
+	return nil
+}
+
+func (self *jsonDecoder) object(src any, tar r.Value, ptr string) error {
+	typ := tar.Type()
+	isSchema := typ == typeSchema
+
+	if isSchema {
+		val, ok := src.(bool)
+		if ok {
+			tar.Set(r.ValueOf(BoolSchema(val)))
+			return nil
+		}
+	}
+
+	obj, ok := src.(map[string]any)
+	if !ok {
+		return errJsonType(ptr, `object`, src)
+	}
+
+	var sch *Schema
+	if isSchema {
+		sch = tar.Addr().Interface().(*Schema)
+	}
+
+	fields := jsonFieldsOf(typ)
+
+outer:
+	for _, key := range sortedKeys(obj) {
+		val := obj[key]
+		keyPtr := ptrAppend(ptr, key)
+
+		if sch != nil {
+			done, err := self.schemaKeyword(sch, key, val, keyPtr)
+			if err != nil {
+				return err
+			}
+			if done {
+				continue
+			}
+		}
+
+		for _, field := range fields {
+			if !field.inline && field.name == key {
+				fieldVal := tar.FieldByIndex(field.index)
+				if val == nil && fieldVal.Kind() == r.Interface {
+					fieldVal.Set(r.ValueOf(Null{}))
+					continue outer
+				}
+
+				var err error
+				if field.side != nil && val != nil {
+					err = self.mapping(val, tar.FieldByIndex(field.index), tar.FieldByIndex(field.side), keyPtr)
+				} else {
+					err = self.any(val, tar.FieldByIndex(field.index), keyPtr)
+				}
+				if err != nil {
+					return err
+				}
+				continue outer
+			}
+		}
+
+		// Decoded as a schema, like `oas.MediaType.ItemSchema`, so that its
+		// references are found.
+		if key == ExtItemSchema && typ == typeMediaType {
+			var item Schema
+			err := self.object(val, r.ValueOf(&item).Elem(), keyPtr)
+			if err != nil {
+				return err
+			}
+			tar.Addr().Interface().(*MediaType).Exts.Init()[key] = item
+			continue
+		}
+
+		field, ok := jsonInlineFor(fields, key, isSchema)
+		if !ok {
+			return DecodeErr{keyPtr, errors.New(`unrecognized property`)}
+		}
+
+		err := self.entry(key, val, tar.FieldByIndex(field.index), keyPtr)
+		if err != nil {
+			return err
+		}
+	}
+
+	if sch != nil {
+		self.schemaFinish(sch, obj)
+	}
+	return nil
+}
+
+/*
+Chooses the inline map for an unrecognized key. Extensions go into `.Exts`.
+Other keys go into another inline map, such as `oas.Callback.Paths`, if any.
+Schemas may have arbitrary unknown keywords, which are also kept in `.Exts`.
+*/
+func jsonInlineFor(fields []jsonField, key string, isSchema bool) (jsonField, bool) {
+	isExt := strings.HasPrefix(key, `x-`) || isSchema
+
+	for _, field := range fields {
+		if field.inline && field.exts == isExt {
+			return field, true
+		}
+	}
+	return jsonField{}, false
+}
+
+/*
+Handles schema keywords whose JSON shapes differ from our field types, and
+3.0-specific keywords. Returns true if the keyword was fully handled.
+*/
+func (self *jsonDecoder) schemaKeyword(sch *Schema, key string, val any, ptr string) (bool, error) {
+	switch key {
+	case `type`:
+		str, ok := val.(string)
+		if ok {
+			sch.Type = []string{str}
+			return true, nil
+		}
+
+	case `exclusiveMaximum`, `exclusiveMinimum`:
+		if !self.v30 {
+			return false, nil
+		}
+		// Handled in `schemaFinish`.
+		_, ok := val.(bool)
+		return ok, nil
+
+	case `nullable`:
+		if !self.v30 {
+			return false, nil
+		}
+		_, ok := val.(bool)
+		if !ok {
+			return false, errJsonType(ptr, `boolean`, val)
+		}
+		return true, nil
+	}
+	return false, nil
+}
+
+func (self *jsonDecoder) schemaFinish(sch *Schema, obj map[string]any) {
+	if !self.v30 {
+		return
+	}
+
+	if obj[`exclusiveMaximum`] == true {
+		sch.ExlcMax, sch.Max = sch.Max, nil
+	}
+
+	if obj[`exclusiveMinimum`] == true {
+		sch.ExclMin, sch.Min = sch.Min, nil
+	}
+
+	// Since 3.0.3, "nullable" has no effect without "type", and is dropped.
+	if obj[`nullable`] == true && len(sch.Type) > 0 {
+		sch.TypeAdd(TypeNull)
+		if len(sch.Enum) > 0 && !enumHasNull(sch.Enum) {
+			sch.Enum = append(sch.Enum, nil)
+		}
+	}
+}
+
+func enumHasNull(vals []any) bool {
+	for _, val := range vals {
+		if val == nil {
+			return true
+		}
+	}
+	return false
+}
+
+func sortedKeys[A any](src map[string]A) []string {
+	out := make([]string, 0, len(src))
+	for key := range src {
+		out = append(out, key)
+	}
+	sort.Strings(out)
+	return out
+}
+
+func errJsonType(ptr, exp string, src any) error {
+	return DecodeErr{ptr, fmt.Errorf(`expected %v, got %v`, exp, jsonKind(src))}
+}
+
+func errJsonUnsupported(ptr string, typ r.Type) error {
+	return DecodeErr{ptr, fmt.Errorf(`unsupported target type %q`, typ)}
+}
+
+func jsonKind(src any) string {
+	switch src.(type) {
+	case nil:
+		return `null`
+	case bool:
+		return `boolean`
+	case json.Number:
+		return `number`
+	case string:
+		return `string`
+	case []any:
+		return `array`
+	case map[string]any:
+		return `object`
+	default:
+		return fmt.Sprintf(`%T`, src)
+	}
+}
@@ -0,0 +1,157 @@
+package oas
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Severity of a lint diagnostic. Higher is more severe.
+type Sev byte
+
+const (
+	SevInfo Sev = iota
+	SevWarn
+	SevError
+)
+
+// Implement `fmt.Stringer`.
+func (self Sev) String() string {
+	switch self {
+	case SevInfo:
+		return `info`
+	case SevWarn:
+		return `warning`
+	case SevError:
+		return `error`
+	default:
+		return fmt.Sprintf(`Sev(%d)`, byte(self))
+	}
+}
+
+/*
+Lint rule used by `oas.Lint`. `.Check` inspects the document and calls `report`
+for every problem, with a JSON Pointer to the problematic location. The
+document must not be modified.
+*/
+type LintRule struct {
+	Name  string
+	Sev   Sev
+	Check func(doc *Doc, report func(ptr, msg string))
+}
+
+// Options for `oas.Lint`.
+type LintOpt struct {
+	// Additional rules to run after the built-in rules.
+	Rules []LintRule
+
+	// Names of rules to skip, including built-in rules.
+	Skip []string
+
+	// Overrides for rule severities, by rule name.
+	Sev map[string]Sev
+}
+
+// Diagnostic produced by `oas.Lint`.
+type Diag struct {
+	Rule string
+	Sev  Sev
+	Ptr  string
+	Msg  string
+}
+
+// Implement `fmt.Stringer`.
+func (self Diag) String() string {
+	return fmt.Sprintf(`%v at %q: %v (%v)`, self.Sev, self.Ptr, self.Msg, self.Rule)
+}
+
+// List of diagnostics produced by `oas.Lint`.
+type Diags []Diag
+
+/*
+Returns an error listing the diagnostics with the given severity or higher,
+or nil if there are none. Convenient in tests:
+
+	err := oas.Lint(&doc, oas.LintOpt{}).Err(oas.SevWarn)
+	if err != nil {
+		t.Fatal(err)
+	}
+*/
+func (self Diags) Err(min Sev) error {
+	var buf strings.Builder
+	var count int
+
+	for _, val := range self {
+		if val.Sev < min {
+			continue
+		}
+		count++
+		buf.WriteString("\n\t")
+		buf.WriteString(val.String())
+	}
+
+	if count == 0 {
+		return nil
+	}
+	return fmt.Errorf(`[oas] lint found %d problems:%v`, count, buf.String())
+}
+
+/*
+Checks the document against the built-in rules listed in `oas.LintRules` and
+the additional rules in `oas.LintOpt.Rules`. Diagnostics are ordered by rule,
+then by location within the document.
+*/
+func Lint(doc *Doc, opt LintOpt) (out Diags) {
+	for _, rule := range append(LintRules(), opt.Rules...) {
+		if stringsContain(opt.Skip, rule.Name) {
+			continue
+		}
+
+		sev, ok := opt.Sev[rule.Name]
+		if !ok {
+			sev = rule.Sev
+		}
+
+		rule.Check(doc, func(ptr, msg string) {
+			out = append(out, Diag{Rule: rule.Name, Sev: sev, Ptr: ptr, Msg: msg})
+		})
+	}
+	return
+}
+
+/*
+Returns the built-in lint rules:
+
+  - "op-desc": operation without description. Summaries are not enough,
+    because `oas.Paths.Route` fills them automatically.
+  - "op-id-missing": operation without `operationId`.
+  - "op-id-duplicate": `operationId` used by multiple operations.
+  - "op-success": operation without a 2XX response.
+  - "path-params": path template parameters not declared as path parameters,
+    or path parameters not present in the template.
+  - "unused-comps": components unreachable from the rest of the document.
+    See `oas.Doc.Reachable`. Security schemes are exempt.
+  - "path-kebab": path segments which are not lowercase kebab-case.
+  - "info-version": missing `info.version`.
+  - "op-version": operation whose method requires OpenAPI 3.2, such as
+    "QUERY" or any method in `oas.Path.AddOps`, while `.Openapi` targets an
+    earlier version.
+  - "schema-go-name": schema titles and component names which look like Go
+    type names such as "pkg.Type" or "[]pkg.Type", which leak implementation
+    details. Common for schemas generated from Go types without custom titles.
+
+Returns a new slice on every call; modifications don't affect `oas.Lint`.
+*/
+func LintRules() []LintRule {
+	return []LintRule{
+		{`op-desc`, SevWarn, lintOpDesc},
+		{`op-id-missing`, SevWarn, lintOpIdMissing},
+		{`op-id-duplicate`, SevError, lintOpIdDuplicate},
+		{`op-success`, SevWarn, lintOpSuccess},
+		{`path-params`, SevError, lintPathParams},
+		{`unused-comps`, SevWarn, lintUnusedComps},
+		{`path-kebab`, SevInfo, lintPathKebab},
+		{`info-version`, SevError, lintInfoVersion},
+		{`op-version`, SevError, lintOpVersion},
+		{`schema-go-name`, SevInfo, lintSchemaGoName},
+	}
+}
@@ -0,0 +1,160 @@
+package oas
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Calls the given function for every operation in paths and webhooks.
+func lintOps(doc *Doc, fun func(ptr, path string, op *Op, item *Path)) {
+	each := func(ptr string, paths Paths) {
+		for _, key := range sortedKeys(paths) {
+			item := paths[key]
+			item.Each(func(meth string, op *Op) {
+				fun(pathOpPtr(ptrAppend(ptr, key), meth), key, op, &item)
+			})
+		}
+	}
+	each(`/paths`, doc.Paths)
+	each(`/webhooks`, doc.Webhooks)
+}
+
+func lintOpDesc(doc *Doc, report func(string, string)) {
+	lintOps(doc, func(ptr, _ string, op *Op, _ *Path) {
+		if op.Desc == `` {
+			report(ptr, `operation has no description`)
+		}
+	})
+}
+
+func lintOpIdMissing(doc *Doc, report func(string, string)) {
+	lintOps(doc, func(ptr, _ string, op *Op, _ *Path) {
+		if op.OpId == `` {
+			report(ptr, `operation has no operationId`)
+		}
+	})
+}
+
+func lintOpIdDuplicate(doc *Doc, report func(string, string)) {
+	found := map[string]string{}
+	lintOps(doc, func(ptr, _ string, op *Op, _ *Path) {
+		if op.OpId == `` {
+			return
+		}
+		prev, ok := found[op.OpId]
+		if ok {
+			report(ptrAppend(ptr, `operationId`), `operationId `+strconv.Quote(op.OpId)+` is already used at `+strconv.Quote(prev))
+			return
+		}
+		found[op.OpId] = ptr
+	})
+}
+
+func lintOpSuccess(doc *Doc, report func(string, string)) {
+	lintOps(doc, func(ptr, _ string, op *Op, _ *Path) {
+		for key := range op.Resps {
+			if strings.HasPrefix(key, `2`) {
+				return
+			}
+		}
+		report(ptrAppend(ptr, `responses`), `operation has no 2XX response`)
+	})
+}
+
+func lintPathParams(doc *Doc, report func(string, string)) {
+	lintOps(doc, func(ptr, path string, op *Op, item *Path) {
+		declared := map[string]bool{}
+		for _, list := range [][]Param{item.Params, op.Params} {
+			for _, param := range list {
+				if param.Ref != `` {
+					val, err := ResolveAs[Param](doc, param.Ref)
+					if err != nil {
+						continue
+					}
+					param = val
+				}
+				if param.In == InPath {
+					declared[param.Name] = true
+				}
+			}
+		}
+
+		used := map[string]bool{}
+		for _, match := range pathParam.FindAllStringSubmatch(path, -1) {
+			name := match[1]
+			used[name] = true
+			if !declared[name] {
+				report(ptr, `path parameter `+strconv.Quote(name)+` is not declared`)
+			}
+		}
+
+		for _, name := range sortedKeys(declared) {
+			if !used[name] {
+				report(ptr, `path parameter `+strconv.Quote(name)+` is not in the path template`)
+			}
+		}
+	})
+}
+
+func lintUnusedComps(doc *Doc, report func(string, string)) {
+	for _, ref := range compsUnreachable(doc) {
+		report(ref[1:], `component is not referenced`)
+	}
+}
+
+var lintKebab = regexp.MustCompile(`^[a-z0-9]+(?:-[a-z0-9]+)*$`)
+
+func lintPathKebab(doc *Doc, report func(string, string)) {
+	for _, key := range sortedKeys(doc.Paths) {
+		for _, seg := range strings.Split(key, `/`) {
+			if seg == `` || pathParam.MatchString(seg) || lintKebab.MatchString(seg) {
+				continue
+			}
+			report(ptrAppend(`/paths`, key), `path segment `+strconv.Quote(seg)+` is not kebab-case`)
+		}
+	}
+}
+
+func lintOpVersion(doc *Doc, report func(string, string)) {
+	each := func(ptr string, paths Paths) {
+		for _, key := range sortedKeys(paths) {
+			for _, meth := range paths[key].Methods() {
+				if !doc.methodAllowed(meth) {
+					report(pathOpPtr(ptrAppend(ptr, key), meth), doc.methodVerMsg(meth))
+				}
+			}
+		}
+	}
+	each(`/paths`, doc.Paths)
+	each(`/webhooks`, doc.Webhooks)
+}
+
+func lintInfoVersion(doc *Doc, report func(string, string)) {
+	if doc.Info == nil || doc.Info.Ver == `` {
+		report(`/info`, `missing info.version`)
+	}
+}
+
+/*
+Matches Go type names as produced by `reflect.Type.String`, such as "[]pkg.Type"
+or "pkg.Type", but not host names such as "example.com", since package names
+are lowercase and exported type names are capitalized.
+*/
+var lintGoName = regexp.MustCompile(`^(?:\*|\[\d*\]|map\[)|(?:^|[^\w.])[a-z_]\w*\.[A-Z]\w*(?:$|[^\w.])`)
+
+func lintSchemaGoName(doc *Doc, report func(string, string)) {
+	for _, key := range sortedKeys(doc.Comps.Schemas) {
+		if lintGoName.MatchString(key) {
+			report(ptrAppend(`/components/schemas`, key), `component name `+strconv.Quote(key)+` looks like a Go type name`)
+		}
+	}
+
+	_ = doc.Walk(func(ptr string, val any) error {
+		sch, _ := val.(*Schema)
+		if sch != nil && lintGoName.MatchString(sch.Title) {
+			report(ptrAppend(ptr, `title`), `schema title `+strconv.Quote(sch.Title)+` looks like a Go type name`)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,123 @@
+package oas
+
+import (
+	"net/url"
+	"strings"
+)
+
+/*
+Finds the path template matching the given request path, and returns the
+template, which is a key in `oas.Paths`, and the values of path parameters.
+The input must be percent-encoded, as returned by `(*url.URL).EscapedPath`;
+parameter values are unescaped. As required by the spec, concrete paths take
+priority over templated paths; among templated paths, those with fewer
+parameters take priority. Each parameter matches a non-empty part of a single
+path segment. Example:
+
+	tpl, params, ok := doc.Paths.Match(`/users/123`)
+	// tpl == `/users/{id}`
+	// params == map[string]string{`id`: `123`}
+*/
+func (self Paths) Match(path string) (tpl string, params map[string]string, ok bool) {
+	var count int
+
+	for key := range self {
+		vals, found := pathMatch(key, path)
+		if !found {
+			continue
+		}
+
+		if !ok || len(vals) < count || (len(vals) == count && key < tpl) {
+			tpl, params, ok, count = key, vals, true, len(vals)
+		}
+	}
+
+	for key, val := range params {
+		out, err := url.PathUnescape(val)
+		if err == nil {
+			params[key] = out
+		}
+	}
+	return
+}
+
+/*
+Returns the operation for the given HTTP method, or nil if there is none,
+including operations in `.AddOps`. Methods are case-sensitive. Unlike
+`oas.Path.Method`, doesn't panic on unknown methods.
+*/
+func (self Path) Op(meth string) *Op {
+	ptr := self.opPtr(meth)
+	if ptr == nil {
+		return self.AddOps[meth]
+	}
+	return *ptr
+}
+
+/*
+Returns the HTTP methods which have operations: methods with dedicated fields
+in the spec order, followed by methods in `.AddOps`, sorted.
+*/
+func (self Path) Methods() []string { return pathMethodsOf(self) }
+
+/*
+Calls the given function for every operation, in the order of
+`oas.Path.Methods`. The operations are mutable.
+*/
+func (self Path) Each(fun func(meth string, op *Op)) {
+	for _, meth := range self.Methods() {
+		fun(meth, self.Op(meth))
+	}
+}
+
+/*
+Finds the media type matching the given content type, such as the
+"Content-Type" of a request. Parameters such as "charset" are ignored, and
+comparison is case-insensitive. Exact matches take priority over subtype
+wildcards such as "text/*", which take priority over the full wildcard.
+Returns the matching key.
+*/
+func (self MediaTypes) Match(conType string) (string, MediaType, bool) {
+	base := mediaBase(conType)
+	if base == `` {
+		return ``, MediaType{}, false
+	}
+
+	typ, _, _ := strings.Cut(base, `/`)
+	var key string
+	var rank int
+
+	for cand := range self {
+		candBase := mediaBase(cand)
+		var candRank int
+
+		switch candBase {
+		case base:
+			candRank = 3
+		case typ + `/*`:
+			candRank = 2
+		case `*/*`:
+			candRank = 1
+		default:
+			continue
+		}
+
+		if candRank > rank || (candRank == rank && cand < key) {
+			key, rank = cand, candRank
+		}
+	}
+
+	if rank == 0 {
+		return ``, MediaType{}, false
+	}
+	return key, self[key], true
+}
+
+/*
+True if the given content type is JSON: "application/json" or any type with
+the "+json" suffix, such as "application/problem+json".
+*/
+func IsConTypeJson(val string) bool {
+	val = mediaBase(val)
+	return val == ConTypeJson || strings.HasSuffix(val, `+json`)
+}
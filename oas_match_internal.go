@@ -0,0 +1,85 @@
+package oas
+
+import (
+	"regexp"
+	"strings"
+	"sync"
+)
+
+/*
+Matches a path template against a request path. Returns the raw (escaped)
+values of path parameters, which is non-nil for any match.
+*/
+func pathMatch(tpl, path string) (map[string]string, bool) {
+	out := map[string]string{}
+
+	for {
+		tplSeg, tplRest, tplMore := strings.Cut(tpl, `/`)
+		pathSeg, pathRest, pathMore := strings.Cut(path, `/`)
+
+		if !strings.Contains(tplSeg, `{`) {
+			if tplSeg != pathSeg {
+				return nil, false
+			}
+		} else {
+			reg, names := pathSegRegexp(tplSeg)
+			match := reg.FindStringSubmatch(pathSeg)
+			if match == nil {
+				return nil, false
+			}
+			for ind, name := range names {
+				out[name] = match[ind+1]
+			}
+		}
+
+		if tplMore != pathMore {
+			return nil, false
+		}
+		if !tplMore {
+			return out, true
+		}
+		tpl, path = tplRest, pathRest
+	}
+}
+
+type pathSeg struct {
+	reg   *regexp.Regexp
+	names []string
+}
+
+var pathSegCache sync.Map
+
+var pathParam = regexp.MustCompile(`\{([^{}]+)\}`)
+
+// Compiles a template segment such as "{name}.json" into a regexp.
+func pathSegRegexp(src string) (*regexp.Regexp, []string) {
+	val, ok := pathSegCache.Load(src)
+	if ok {
+		seg := val.(pathSeg)
+		return seg.reg, seg.names
+	}
+
+	var buf strings.Builder
+	var names []string
+	var prev int
+
+	buf.WriteString(`^`)
+	for _, loc := range pathParam.FindAllStringSubmatchIndex(src, -1) {
+		buf.WriteString(regexp.QuoteMeta(src[prev:loc[0]]))
+		buf.WriteString(`([^/]+?)`)
+		names = append(names, src[loc[2]:loc[3]])
+		prev = loc[1]
+	}
+	buf.WriteString(regexp.QuoteMeta(src[prev:]))
+	buf.WriteString(`$`)
+
+	seg := pathSeg{regexp.MustCompile(buf.String()), names}
+	pathSegCache.Store(src, seg)
+	return seg.reg, seg.names
+}
+
+// Lowercased media type without parameters.
+func mediaBase(val string) string {
+	val, _, _ = strings.Cut(val, `;`)
+	return strings.ToLower(strings.TrimSpace(val))
+}
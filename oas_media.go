@@ -0,0 +1,89 @@
+package oas
+
+import (
+	r "reflect"
+	"strings"
+)
+
+/*
+Returns `oas.MediaTypes` describing the same Go type in several content types,
+for resources available in multiple representations chosen via content
+negotiation. The input is used only as a type carrier. If no content types
+are given, uses "application/json". JSON types use `oas.Doc.SchemaMedia`, XML
+types use `oas.Doc.XmlMedia`, other "text/*" types such as "text/csv" are
+described as strings, and remaining types have no schema. Example:
+
+	doc.Route(`/persons`, http.MethodGet, oas.Op{
+		Resps: doc.Responses().
+			Media(`200`, []Person(nil), ``, oas.ConTypeJson, oas.ConTypeXml, oas.ConTypeCsv).
+			Done(),
+	})
+*/
+func (self *Doc) Media(typ any, conTypes ...string) MediaTypes {
+	if len(conTypes) == 0 {
+		conTypes = []string{ConTypeJson}
+	}
+
+	out := MediaTypes{}
+	for _, key := range conTypes {
+		switch {
+		case IsConTypeJson(key):
+			out[key] = self.SchemaMedia(typ)
+		case IsConTypeXml(key):
+			out[key] = self.XmlMedia(typ)
+		case strings.HasPrefix(mediaBase(key), `text/`):
+			out[key] = MediaType{Schema: Schema{Type: []string{TypeStr}}}
+		default:
+			out[key] = MediaType{}
+		}
+	}
+	return out
+}
+
+/*
+Like `oas.Doc.Sch`, but also describes the XML representation of the type, as
+produced by "encoding/xml", by setting `oas.Schema.Xml` in the schemas of
+struct types and their properties, derived from `xml` struct tags:
+
+  - Root element names and namespaces come from `XMLName` fields, defaulting
+    to the Go type name.
+  - Element names and namespaces of fields, such as `xml:"urn:ns name"`,
+    default to Go field names.
+  - `xml:",attr"` describes attributes.
+  - Parent chains of slice fields, such as `xml:"tags>tag"`, describe wrapped
+    arrays.
+
+Properties come from the JSON schema of the type, keyed by JSON names, so
+fields skipped by "encoding/json" are not described. Fields using "chardata",
+"innerxml", "comment", "any", or parent chains of non-slices, have no
+equivalent in OpenAPI and are left unannotated. The XML object doesn't affect
+JSON validation, so the annotated components are shared with the JSON
+representation.
+*/
+func (self *Doc) XmlSchema(typ any) Schema {
+	out := self.Sch(typ)
+	self.xmlAnnotate(r.TypeOf(typ), map[r.Type]bool{})
+	return out
+}
+
+// Shortcut. Returns `oas.MediaType` with the schema from `oas.Doc.XmlSchema`.
+func (self *Doc) XmlMedia(typ any) MediaType {
+	return MediaType{Schema: self.XmlSchema(typ)}
+}
+
+/*
+True if the given content type is XML: "application/xml", "text/xml", or any
+type with the "+xml" suffix, such as "application/atom+xml".
+*/
+func IsConTypeXml(val string) bool {
+	val = mediaBase(val)
+	return val == ConTypeXml || val == `text/xml` || strings.HasSuffix(val, `+xml`)
+}
+
+/*
+Adds a response with a body of the given type in each of the given content
+types. See `oas.Doc.Media`.
+*/
+func (self *RespsBuilder) Media(status string, typ any, desc string, conTypes ...string) *RespsBuilder {
+	return self.Status(status, Resp{Desc: desc, Cont: self.doc.Media(typ, conTypes...)})
+}
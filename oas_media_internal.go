@@ -0,0 +1,176 @@
+package oas
+
+import (
+	"encoding/xml"
+	r "reflect"
+	"strings"
+)
+
+var typeXmlName = r.TypeOf(xml.Name{})
+
+// Parsed `xml` struct tag. See "encoding/xml".
+type xmlTag struct {
+	nspace string
+	name   string
+	parent string
+	attr   bool
+	skip   bool
+}
+
+func xmlTagParse(src string) (out xmlTag) {
+	name, flags, _ := strings.Cut(src, `,`)
+
+	if ind := strings.IndexByte(name, ' '); ind >= 0 {
+		out.nspace, name = name[:ind], name[ind+1:]
+	}
+	if ind := strings.LastIndexByte(name, '>'); ind >= 0 {
+		out.parent, name = name[:ind], name[ind+1:]
+	}
+	out.name = name
+
+	for _, flag := range strings.Split(flags, `,`) {
+		switch flag {
+		case `attr`:
+			out.attr = true
+		case `chardata`, `cdata`, `innerxml`, `comment`, `any`:
+			out.skip = true
+		}
+	}
+	return
+}
+
+/*
+Sets `oas.Schema.Xml` in the component schema of the given struct type, after
+dereferencing pointers and collections, and recursively in the components of
+its field types.
+*/
+func (self *Doc) xmlAnnotate(typ r.Type, done map[r.Type]bool) {
+	typ = xmlElem(typ)
+	if typ == nil || typ.Kind() != r.Struct || done[typ] {
+		return
+	}
+	done[typ] = true
+
+	name := typeName(typ)
+	sch, ok := self.Comps.Schemas[name]
+	if !ok || sch.Ref != `` || !sch.TypeHas(TypeObj) {
+		return
+	}
+
+	sch.Xml = xmlRoot(typ)
+	self.xmlProps(&sch, typ, done)
+	self.Comps.Schemas[name] = sch
+}
+
+func xmlElem(typ r.Type) r.Type {
+	for typ != nil {
+		switch typ.Kind() {
+		case r.Ptr, r.Slice, r.Array:
+			typ = typ.Elem()
+		default:
+			return typ
+		}
+	}
+	return nil
+}
+
+func xmlRoot(typ r.Type) *Xml {
+	out := Xml{Name: typ.Name()}
+
+	field, ok := typ.FieldByName(`XMLName`)
+	if ok && field.Type == typeXmlName {
+		tag := xmlTagParse(field.Tag.Get(`xml`))
+		if tag.name != `` {
+			out.Name = tag.name
+		}
+		out.Nspace = tag.nspace
+	}
+	return &out
+}
+
+func (self *Doc) xmlProps(sch *Schema, typ r.Type, done map[r.Type]bool) {
+	for ind := range iter(typ.NumField()) {
+		field := typ.Field(ind)
+		src := field.Tag.Get(`xml`)
+
+		if !isPublic(field.PkgPath) || src == `-` || field.Name == `XMLName` {
+			continue
+		}
+
+		tag := xmlTagParse(src)
+		key := jsonName(field)
+
+		if field.Anonymous && key == `` && tag.name == `` {
+			inner := typeDeref(field.Type)
+			if inner.Kind() == r.Struct {
+				self.xmlProps(sch, inner, done)
+				continue
+			}
+		}
+
+		self.xmlAnnotate(field.Type, done)
+
+		if key == `` {
+			key = field.Name
+		}
+		prop, ok := sch.Props[key]
+		if !ok || tag.skip {
+			continue
+		}
+		if tag.name == `` {
+			tag.name = field.Name
+		}
+		sch.Props[key] = self.xmlProp(prop, key, tag, field.Type)
+	}
+}
+
+/*
+XML elements of arrays are named via their items. The property schema of a
+slice usually references a component shared with other properties, so it's
+inlined to annotate the items.
+*/
+func (self *Doc) xmlProp(prop Schema, key string, tag xmlTag, typ r.Type) Schema {
+	name := tag.name
+	if name == key {
+		name = ``
+	}
+
+	if xmlIsArr(typ) && !tag.attr {
+		if tag.parent != `` {
+			if strings.Contains(tag.parent, `>`) {
+				return prop
+			}
+			out := self.xmlItems(prop, Xml{Name: tag.name, Nspace: tag.nspace})
+			out.Xml = &Xml{Name: tag.parent, Wrap: true}
+			return out
+		}
+		if name == `` && tag.nspace == `` {
+			return prop
+		}
+		return self.xmlItems(prop, Xml{Name: tag.name, Nspace: tag.nspace})
+	}
+
+	if tag.parent != `` || (name == `` && tag.nspace == `` && !tag.attr) {
+		return prop
+	}
+	prop.Xml = &Xml{Name: name, Nspace: tag.nspace, Attr: tag.attr}
+	return prop
+}
+
+func (self *Doc) xmlItems(prop Schema, val Xml) Schema {
+	out, ok := self.DerefSchema(prop)
+	if !ok || out.Items == nil {
+		return prop
+	}
+
+	items := *out.Items
+	items.Xml = &val
+	out.Items = &items
+	return out
+}
+
+// Byte slices are encoded as single values, like strings.
+func xmlIsArr(typ r.Type) bool {
+	typ = typeDeref(typ)
+	return (typ.Kind() == r.Slice || typ.Kind() == r.Array) && typ.Elem().Kind() != r.Uint8
+}
@@ -0,0 +1,102 @@
+package oas
+
+import (
+	"errors"
+	"fmt"
+	r "reflect"
+)
+
+// Strategy used by `oas.Doc.Merge` for conflicting definitions.
+type MergeMode byte
+
+const (
+	// Conflicts are reported as errors. This is the default.
+	MergeStrict MergeMode = iota
+
+	// Conflicts are resolved by keeping the definition already in the target.
+	MergeLeft
+
+	/**
+	Conflicting components and tags from the source are renamed by adding
+	`oas.MergeOpt.Prefix`, and references to them are updated. Conflicts which
+	can't be renamed, such as paths, are reported as errors.
+	*/
+	MergeRename
+)
+
+// Options for `oas.Doc.Merge` and `oas.MergeDocs`.
+type MergeOpt struct {
+	Mode   MergeMode
+	Prefix string
+}
+
+/*
+Merges the given document into this one. Combines paths (per method),
+webhooks, all component maps, tags (by name), servers and extensions.
+Top-level singular fields such as `.Openapi` and `.Info` are copied from the
+source only when missing in the target.
+
+Top-level security requirements are kept when they're identical. Otherwise,
+each document's requirements are copied onto its operations without `.Sec`,
+and the merged document has none, so that neither document's operations
+become accessible with the other's requirements. A document without
+operations defers to the requirements of the other.
+
+Definitions which exist in both documents under the same name (or the same
+path and method) with different content are conflicts, handled according to
+`oas.MergeOpt.Mode`. Paths which differ only in parameter names, such as
+"/users/{id}" and "/users/{key}", are also conflicts. Identical definitions
+are deduplicated. Errors are combined via `errors.Join` and are of type
+`oas.MergeErr`. On error, the receiver is left unchanged. The source document
+is never mutated.
+*/
+func (self *Doc) Merge(src Doc, opt MergeOpt) error {
+	tar := self.Clone()
+	err := merger{MergeOpt: opt}.doc(&tar, src.Clone())
+	if err != nil {
+		return err
+	}
+	*self = tar
+	return nil
+}
+
+/*
+Shortcut for merging any number of documents, left to right, into an empty
+document. See `oas.Doc.Merge`.
+*/
+func MergeDocs(opt MergeOpt, docs ...Doc) (out Doc, err error) {
+	for _, doc := range docs {
+		err = out.Merge(doc, opt)
+		if err != nil {
+			return Doc{}, err
+		}
+	}
+	return
+}
+
+/*
+Returns a deep copy of the document. Values of "any" type, such as examples,
+are copied recursively, except for structs of other packages, which are
+copied shallowly.
+*/
+func (self Doc) Clone() Doc { return cloneValue(r.ValueOf(self)).Interface().(Doc) }
+
+/*
+Error type returned by `oas.Doc.Merge`. `.Ptr` is a JSON Pointer to the
+conflicting location in the merged document.
+*/
+type MergeErr struct {
+	Ptr   string
+	Cause error
+}
+
+// Implement `error`.
+func (self MergeErr) Error() string {
+	return fmt.Sprintf(`[oas] failed to merge at %q: %v`, self.Ptr, self.Cause)
+}
+
+// Implement a hidden interface in "errors".
+func (self MergeErr) Unwrap() error { return self.Cause }
+
+// Cause of `oas.MergeErr` for definitions which differ between documents.
+var ErrMergeConflict = errors.New(`conflicting definitions`)
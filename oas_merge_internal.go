@@ -0,0 +1,373 @@
+package oas
+
+import (
+	"errors"
+	"fmt"
+	r "reflect"
+)
+
+type merger struct {
+	MergeOpt
+	errs []error
+}
+
+func (self merger) doc(tar *Doc, src Doc) error {
+	if self.Mode == MergeRename {
+		if self.Prefix == `` {
+			return errors.New(`[oas] merge mode "rename" requires a prefix`)
+		}
+		for self.renameComps(tar, &src) {
+		}
+		self.renameTags(tar, &src)
+		if len(self.errs) > 0 {
+			return errors.Join(self.errs...)
+		}
+	}
+
+	self.str(`/$ref`, &tar.Ref, src.Ref)
+	self.str(`/summary`, &tar.Sum, src.Sum)
+	self.str(`/description`, &tar.Desc, src.Desc)
+	fill(&tar.Openapi, src.Openapi)
+	fill(&tar.Info, src.Info)
+	fill(&tar.JsonSchema, src.JsonSchema)
+	fill(&tar.ExtDoc, src.ExtDoc)
+	tar.Servers = union(tar.Servers, src.Servers)
+	self.security(tar, &src)
+	self.paths(`/paths`, &tar.Paths, src.Paths)
+	self.mapping(`/paths`, r.ValueOf(&tar.PathsExts).Elem(), r.ValueOf(src.PathsExts))
+	self.paths(`/webhooks`, &tar.Webhooks, src.Webhooks)
+	self.comps(&tar.Comps, src.Comps)
+	self.tags(tar, src.Tags)
+	self.mapping(``, r.ValueOf(&tar.Exts).Elem(), r.ValueOf(src.Exts))
+	return errors.Join(self.errs...)
+}
+
+func (self *merger) conflict(ptr string) {
+	if self.Mode != MergeLeft {
+		self.errs = append(self.errs, MergeErr{ptr, ErrMergeConflict})
+	}
+}
+
+func (self *merger) str(ptr string, tar *string, src string) {
+	if *tar != `` && src != `` && *tar != src {
+		self.conflict(ptr)
+		return
+	}
+	fill(tar, src)
+}
+
+/*
+Top-level security requirements are alternatives which apply to operations
+without their own requirements. Combining them would loosen the requirements
+of both documents. When they differ, they're copied onto the operations which
+inherit them, unless one of the documents has no operations.
+*/
+func (self *merger) security(tar, src *Doc) {
+	if (len(tar.Security) == 0 && len(src.Security) == 0) ||
+		r.DeepEqual(tar.Security, src.Security) ||
+		!docHasOps(*src) {
+		return
+	}
+	if !docHasOps(*tar) {
+		tar.Security = src.Security
+		return
+	}
+	secInherit(tar)
+	secInherit(src)
+}
+
+func docHasOps(doc Doc) bool {
+	for _, paths := range []Paths{doc.Paths, doc.Webhooks} {
+		for _, path := range paths {
+			if len(path.Methods()) > 0 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Moves top-level security requirements onto the operations which inherit them.
+func secInherit(doc *Doc) {
+	for _, paths := range []Paths{doc.Paths, doc.Webhooks} {
+		for _, path := range paths {
+			path.Each(func(_ string, op *Op) {
+				if op.Sec == nil {
+					op.Sec = union(nil, doc.Security)
+				}
+			})
+		}
+	}
+	doc.Security = nil
+}
+
+/*
+Paths which differ only in parameter names, like "/users/{id}" and
+"/users/{key}", match the same requests, and are conflicts.
+*/
+func (self *merger) paths(ptr string, tar *Paths, src Paths) {
+	norms := make(map[string]string, len(*tar))
+	for key := range *tar {
+		norms[pathNorm(key)] = key
+	}
+
+	for _, key := range sortedKeys(src) {
+		srcPath := src[key]
+		tarPath, ok := (*tar)[key]
+		if !ok {
+			norm := pathNorm(key)
+			if _, ok := norms[norm]; ok {
+				self.conflict(ptrAppend(ptr, key))
+				continue
+			}
+			norms[norm] = key
+			tar.Init()[key] = srcPath
+			continue
+		}
+		self.path(ptrAppend(ptr, key), &tarPath, srcPath)
+		(*tar)[key] = tarPath
+	}
+}
+
+func (self *merger) path(ptr string, tar *Path, src Path) {
+	self.str(ptr+`/$ref`, &tar.Ref, src.Ref)
+	self.str(ptr+`/summary`, &tar.Sum, src.Sum)
+	self.str(ptr+`/description`, &tar.Desc, src.Desc)
+
+	for _, meth := range src.Methods() {
+		tarOp, srcOp := tar.Op(meth), src.Op(meth)
+		if tarOp == nil {
+			tar.setOp(meth, srcOp)
+			continue
+		}
+		if !r.DeepEqual(tarOp, srcOp) {
+			self.conflict(pathOpPtr(ptr, meth))
+		}
+	}
+
+	tar.Servers = union(tar.Servers, src.Servers)
+	tar.Params = union(tar.Params, src.Params)
+	self.mapping(ptr, r.ValueOf(&tar.Exts).Elem(), r.ValueOf(src.Exts))
+}
+
+func (self *merger) comps(tar *Comps, src Comps) {
+	self.str(`/components/$ref`, &tar.Ref, src.Ref)
+	self.str(`/components/summary`, &tar.Sum, src.Sum)
+	self.str(`/components/description`, &tar.Desc, src.Desc)
+
+	tarVal := r.ValueOf(tar).Elem()
+	srcVal := r.ValueOf(src)
+	for _, field := range compsFields() {
+		self.mapping(
+			ptrAppend(`/components`, field.name),
+			tarVal.FieldByIndex(field.index),
+			srcVal.FieldByIndex(field.index),
+		)
+	}
+	self.mapping(`/components`, r.ValueOf(&tar.Exts).Elem(), r.ValueOf(src.Exts))
+}
+
+/*
+Merges a map of definitions keyed by name. Identical definitions are skipped,
+different ones are conflicts.
+*/
+func (self *merger) mapping(ptr string, tar, src r.Value) {
+	if src.Len() == 0 {
+		return
+	}
+	if tar.IsNil() {
+		tar.Set(r.MakeMapWithSize(tar.Type(), src.Len()))
+	}
+
+	for _, key := range sortedMapKeys(src) {
+		srcVal := src.MapIndex(key)
+		tarVal := tar.MapIndex(key)
+
+		if !tarVal.IsValid() {
+			tar.SetMapIndex(key, srcVal)
+			continue
+		}
+		if !r.DeepEqual(tarVal.Interface(), srcVal.Interface()) {
+			self.conflict(ptrAppend(ptr, key.String()))
+		}
+	}
+}
+
+func (self *merger) tags(tar *Doc, src []Tag) {
+outer:
+	for _, srcTag := range src {
+		for ind, tarTag := range tar.Tags {
+			if tarTag.Name != srcTag.Name {
+				continue
+			}
+			if !r.DeepEqual(tarTag, srcTag) {
+				self.conflict(ptrAppend(`/tags`, fmt.Sprint(ind)))
+			}
+			continue outer
+		}
+		tar.Tags = append(tar.Tags, srcTag)
+	}
+}
+
+/*
+Renames the source components which conflict with the target components, and
+updates references to them throughout the source document. Rewriting
+references may cause more conflicts, so the caller repeats this until it
+returns false.
+*/
+func (self *merger) renameComps(tar, src *Doc) bool {
+	tarVal := r.ValueOf(&tar.Comps).Elem()
+	srcVal := r.ValueOf(&src.Comps).Elem()
+	renames := map[string]string{}
+
+	for _, field := range compsFields() {
+		tarMap := tarVal.FieldByIndex(field.index)
+		srcMap := srcVal.FieldByIndex(field.index)
+		prefix := ptrAppend(`#/components`, field.name)
+
+		for _, key := range sortedMapKeys(srcMap) {
+			name := key.String()
+			tarElem := tarMap.MapIndex(key)
+			if !tarElem.IsValid() || r.DeepEqual(tarElem.Interface(), srcMap.MapIndex(key).Interface()) {
+				continue
+			}
+
+			next := r.ValueOf(self.Prefix + name)
+			if tarMap.MapIndex(next).IsValid() || srcMap.MapIndex(next).IsValid() {
+				self.errs = append(self.errs, MergeErr{
+					ptrAppend(prefix[1:], next.String()),
+					errMergeRename(name, next.String()),
+				})
+				continue
+			}
+
+			srcMap.SetMapIndex(next, srcMap.MapIndex(key))
+			srcMap.SetMapIndex(key, r.Value{})
+			renames[ptrAppend(prefix, name)] = ptrAppend(prefix, next.String())
+
+			if field.name == `securitySchemes` {
+				secReqsRename(src, name, next.String())
+			}
+		}
+	}
+
+	if len(renames) == 0 || len(self.errs) > 0 {
+		return false
+	}
+
+	refsRewrite(src, func(ref string) string {
+		for prev, next := range renames {
+			if ref == prev {
+				return next
+			}
+			rest, ok := unprefix(ref, prev+`/`)
+			if ok {
+				return next + `/` + rest
+			}
+		}
+		return ref
+	})
+	return true
+}
+
+/*
+Renames the source tags which conflict with the target tags, and updates the
+tags of the source operations.
+*/
+func (self *merger) renameTags(tar, src *Doc) {
+	renames := map[string]string{}
+
+	for ind, srcTag := range src.Tags {
+		tarTag, ok := tagFind(tar.Tags, srcTag.Name)
+		if !ok || r.DeepEqual(tarTag, srcTag) {
+			continue
+		}
+
+		next := self.Prefix + srcTag.Name
+		_, ok0 := tagFind(tar.Tags, next)
+		_, ok1 := tagFind(src.Tags, next)
+		if ok0 || ok1 {
+			self.errs = append(self.errs, MergeErr{
+				ptrAppend(`/tags`, fmt.Sprint(ind)),
+				errMergeRename(srcTag.Name, next),
+			})
+			continue
+		}
+
+		renames[srcTag.Name] = next
+		src.Tags[ind].Name = next
+	}
+
+	if len(renames) == 0 {
+		return
+	}
+
+	_ = Walk(src, func(_ string, val any) error {
+		op, _ := val.(*Op)
+		if op == nil {
+			return nil
+		}
+		for ind, tag := range op.Tags {
+			next, ok := renames[tag]
+			if ok {
+				op.Tags[ind] = next
+			}
+		}
+		return nil
+	})
+}
+
+func errMergeRename(prev, next string) error {
+	return fmt.Errorf(`unable to rename %q to %q: name already taken`, prev, next)
+}
+
+// Renames the given security scheme in all security requirements.
+func secReqsRename(doc *Doc, prev, next string) {
+	secReqsWalk(doc, func(val SecReq) {
+		elem, ok := val[prev]
+		if ok {
+			val[next] = elem
+			delete(val, prev)
+		}
+	})
+}
+
+func tagFind(src []Tag, name string) (Tag, bool) {
+	for _, val := range src {
+		if val.Name == name {
+			return val, true
+		}
+	}
+	return Tag{}, false
+}
+
+// Fields of `oas.Comps` which are maps of components.
+func compsFields() (out []jsonField) {
+	for _, field := range jsonFieldsOf(r.TypeOf(Comps{})) {
+		if !field.exts && r.TypeOf(Comps{}).FieldByIndex(field.index).Type.Kind() == r.Map {
+			out = append(out, field)
+		}
+	}
+	return
+}
+
+func fill[A comparable](tar *A, src A) {
+	var zero A
+	if *tar == zero {
+		*tar = src
+	}
+}
+
+// Appends the source elements missing from the target, compared deeply.
+func union[A any](tar, src []A) []A {
+outer:
+	for _, srcVal := range src {
+		for _, tarVal := range tar {
+			if r.DeepEqual(tarVal, srcVal) {
+				continue outer
+			}
+		}
+		tar = append(tar, srcVal)
+	}
+	return tar
+}
@@ -2,12 +2,42 @@ package oas
 
 import (
 	"fmt"
-	"net/http"
+	"strings"
 )
 
-// Represents maps of "any type" in some OAS definitions.
+/*
+Represents maps of "any type" in some OAS definitions. Also used for the `.Exts`
+field present in most types, which holds specification extensions (properties
+starting with "x-"). Extensions are encoded inline, as siblings of regular
+properties, by the JSON methods of this package's types and by
+`oas.MarshalYaml`. Reference:
+
+	https://spec.openapis.org/oas/v3.1.0#specification-extensions
+*/
 type Anys map[string]any
 
+/*
+Inits the receiving variable or property to non-nil, returning the resulting
+mutable map. Handy for chaining.
+*/
+func (self *Anys) Init() Anys {
+	if *self == nil {
+		*self = Anys{}
+	}
+	return *self
+}
+
+/*
+Explicit null in fields of type `any`, such as `oas.Schema.Default` or
+`oas.MediaType.Example`, where nil means that the field is absent. Decoding
+JSON produces `oas.Null{}` for such fields set to null, and encoding turns it
+back into null, which allows to express `"default": null`.
+*/
+type Null struct{}
+
+// Implement `json.Marshaler`, encoding as null.
+func (Null) MarshalJSON() ([]byte, error) { return []byte(`null`), nil }
+
 /*
 Reference object. References:
 
@@ -22,49 +52,53 @@ some of which don't seem to support embedded structs, particularly for YAML.
 */
 type Ref struct {
 	Ref  string `json:"$ref,omitempty"        yaml:"$ref,omitempty"        toml:"$ref,omitempty"`
-	Sum  string `json:"sum,omitempty"         yaml:"sum,omitempty"         toml:"sum,omitempty"`
+	Sum  string `json:"summary,omitempty"     yaml:"summary,omitempty"     toml:"summary,omitempty"`
 	Desc string `json:"description,omitempty" yaml:"description,omitempty" toml:"description,omitempty"`
 }
 
 // https://spec.openapis.org/oas/v3.1.0#info-object
 type Info struct {
 	Ref     string   `json:"$ref,omitempty"        yaml:"$ref,omitempty"        toml:"$ref,omitempty"`
-	Sum     string   `json:"sum,omitempty"         yaml:"sum,omitempty"         toml:"sum,omitempty"`
+	Sum     string   `json:"summary,omitempty"     yaml:"summary,omitempty"     toml:"summary,omitempty"`
 	Desc    string   `json:"description,omitempty" yaml:"description,omitempty" toml:"description,omitempty"`
 	Title   string   `json:"title,omitempty"          yaml:"title,omitempty"          toml:"title,omitempty"`
 	Terms   string   `json:"termsOfService,omitempty" yaml:"termsOfService,omitempty" toml:"termsOfService,omitempty"`
 	Contact *Contact `json:"contact,omitempty"        yaml:"contact,omitempty"        toml:"contact,omitempty"`
 	License *License `json:"license,omitempty"        yaml:"license,omitempty"        toml:"license,omitempty"`
 	Ver     string   `json:"version,omitempty"        yaml:"version,omitempty"        toml:"version,omitempty"`
+	Exts    Anys     `json:"-" yaml:",inline" toml:"-"`
 }
 
 // https://spec.openapis.org/oas/v3.1.0#contact-object
 type Contact struct {
 	Ref   string `json:"$ref,omitempty"        yaml:"$ref,omitempty"        toml:"$ref,omitempty"`
-	Sum   string `json:"sum,omitempty"         yaml:"sum,omitempty"         toml:"sum,omitempty"`
+	Sum   string `json:"summary,omitempty"     yaml:"summary,omitempty"     toml:"summary,omitempty"`
 	Desc  string `json:"description,omitempty" yaml:"description,omitempty" toml:"description,omitempty"`
 	Name  string `json:"name,omitempty"  yaml:"name,omitempty"  toml:"name,omitempty"`
 	Url   string `json:"url,omitempty"   yaml:"url,omitempty"   toml:"url,omitempty"`
 	Email string `json:"email,omitempty" yaml:"email,omitempty" toml:"email,omitempty"`
+	Exts  Anys   `json:"-" yaml:",inline" toml:"-"`
 }
 
 // https://spec.openapis.org/oas/v3.1.0#license-object
 type License struct {
 	Ref   string `json:"$ref,omitempty"        yaml:"$ref,omitempty"        toml:"$ref,omitempty"`
-	Sum   string `json:"sum,omitempty"         yaml:"sum,omitempty"         toml:"sum,omitempty"`
+	Sum   string `json:"summary,omitempty"     yaml:"summary,omitempty"     toml:"summary,omitempty"`
 	Desc  string `json:"description,omitempty" yaml:"description,omitempty" toml:"description,omitempty"`
 	Name  string `json:"name,omitempty"       yaml:"name,omitempty"       toml:"name,omitempty"`
 	Ident string `json:"identifier,omitempty" yaml:"identifier,omitempty" toml:"identifier,omitempty"`
 	Url   string `json:"url,omitempty"        yaml:"url,omitempty"        toml:"url,omitempty"`
+	Exts  Anys   `json:"-" yaml:",inline" toml:"-"`
 }
 
 // https://spec.openapis.org/oas/v3.1.0#server-object
 type Server struct {
 	Ref  string `json:"$ref,omitempty"        yaml:"$ref,omitempty"        toml:"$ref,omitempty"`
-	Sum  string `json:"sum,omitempty"         yaml:"sum,omitempty"         toml:"sum,omitempty"`
+	Sum  string `json:"summary,omitempty"     yaml:"summary,omitempty"     toml:"summary,omitempty"`
 	Desc string `json:"description,omitempty" yaml:"description,omitempty" toml:"description,omitempty"`
 	Url  string `json:"url,omitempty"         yaml:"url,omitempty"         toml:"url,omitempty"`
 	Vars Vars   `json:"variables,omitempty"   yaml:"variables,omitempty"   toml:"variables,omitempty"`
+	Exts Anys   `json:"-" yaml:",inline" toml:"-"`
 }
 
 // https://spec.openapis.org/oas/v3.1.0#server-variable-object
@@ -73,17 +107,18 @@ type Vars map[string]Var
 // https://spec.openapis.org/oas/v3.1.0#server-variable-object
 type Var struct {
 	Ref     string   `json:"$ref,omitempty"        yaml:"$ref,omitempty"        toml:"$ref,omitempty"`
-	Sum     string   `json:"sum,omitempty"         yaml:"sum,omitempty"         toml:"sum,omitempty"`
+	Sum     string   `json:"summary,omitempty"     yaml:"summary,omitempty"     toml:"summary,omitempty"`
 	Desc    string   `json:"description,omitempty" yaml:"description,omitempty" toml:"description,omitempty"`
 	Enum    []string `json:"enum,omitempty"        yaml:"enum,omitempty"        toml:"enum,omitempty"`
 	Default string   `json:"default,omitempty"     yaml:"default,omitempty"     toml:"default,omitempty"`
+	Exts    Anys     `json:"-" yaml:",inline" toml:"-"`
 }
 
 // Short for "components":
 // https://spec.openapis.org/oas/v3.1.0#components-object
 type Comps struct {
 	Ref        string     `json:"$ref,omitempty"        yaml:"$ref,omitempty"        toml:"$ref,omitempty"`
-	Sum        string     `json:"sum,omitempty"         yaml:"sum,omitempty"         toml:"sum,omitempty"`
+	Sum        string     `json:"summary,omitempty"     yaml:"summary,omitempty"     toml:"summary,omitempty"`
 	Desc       string     `json:"description,omitempty" yaml:"description,omitempty" toml:"description,omitempty"`
 	Schemas    Schemas    `json:"schemas,omitempty"         yaml:"schemas,omitempty"         toml:"schemas,omitempty"`
 	Resps      Resps      `json:"responses,omitempty"       yaml:"responses,omitempty"       toml:"responses,omitempty"`
@@ -95,6 +130,7 @@ type Comps struct {
 	Links      Links      `json:"links,omitempty"           yaml:"links,omitempty"           toml:"links,omitempty"`
 	Callbacks  Callbacks  `json:"callbacks,omitempty"       yaml:"callbacks,omitempty"       toml:"callbacks,omitempty"`
 	Paths      Paths      `json:"pathItems,omitempty"       yaml:"pathItems,omitempty"       toml:"pathItems,omitempty"`
+	Exts       Anys       `json:"-" yaml:",inline" toml:"-"`
 }
 
 // https://spec.openapis.org/oas/v3.1.0#paths-object
@@ -113,9 +149,31 @@ func (self *Paths) Init() Paths {
 
 /*
 Shortcut for registering an "op" at the given path and method, via
-`(*oas.Path).Method`.
+`(*oas.Path).Method`. Panics if the route is a duplicate; see
+`oas.Paths.TryRoute`.
 */
 func (self Paths) Route(path, meth string, op Op) Paths {
+	err := self.TryRoute(path, meth, op)
+	if err != nil {
+		panic(err)
+	}
+	return self
+}
+
+/*
+Like `oas.Paths.Route`, but returns an error instead of panicking when the
+route is a duplicate, without modifying the paths. A route is a duplicate if
+the path already has an operation for the method, or if another registered
+path differs only in parameter names, such as "/users/{id}" and
+"/users/{userId}", which the spec considers identical regardless of methods.
+To deliberately replace an operation, use `(*oas.Path).Method`.
+*/
+func (self Paths) TryRoute(path, meth string, op Op) error {
+	err := self.routeDup(path, meth)
+	if err != nil {
+		return err
+	}
+
 	/**
 	Tentative. This is useful for many UI visualizers, which would otherwise try
 	to generate a summary from the description, which is annoying in practice.
@@ -128,14 +186,14 @@ func (self Paths) Route(path, meth string, op Op) Paths {
 	val := self[path]
 	val.Method(meth, op)
 	self[path] = val
-	return self
+	return nil
 }
 
 // Called "path item" in the spec:
 // https://spec.openapis.org/oas/v3.1.0#path-item-object
 type Path struct {
 	Ref     string   `json:"$ref,omitempty"        yaml:"$ref,omitempty"        toml:"$ref,omitempty"`
-	Sum     string   `json:"sum,omitempty"         yaml:"sum,omitempty"         toml:"sum,omitempty"`
+	Sum     string   `json:"summary,omitempty"     yaml:"summary,omitempty"     toml:"summary,omitempty"`
 	Desc    string   `json:"description,omitempty" yaml:"description,omitempty" toml:"description,omitempty"`
 	Get     *Op      `json:"get,omitempty"         yaml:"get,omitempty"         toml:"get,omitempty"`
 	Put     *Op      `json:"put,omitempty"         yaml:"put,omitempty"         toml:"put,omitempty"`
@@ -145,45 +203,64 @@ type Path struct {
 	Head    *Op      `json:"head,omitempty"        yaml:"head,omitempty"        toml:"head,omitempty"`
 	Patch   *Op      `json:"patch,omitempty"       yaml:"patch,omitempty"       toml:"patch,omitempty"`
 	Trace   *Op      `json:"trace,omitempty"       yaml:"trace,omitempty"       toml:"trace,omitempty"`
+	Query   *Op      `json:"query,omitempty"       yaml:"query,omitempty"       toml:"query,omitempty"`
+	AddOps  Ops      `json:"additionalOperations,omitempty" yaml:"additionalOperations,omitempty" toml:"additionalOperations,omitempty"`
 	Servers []Server `json:"servers,omitempty"     yaml:"servers,omitempty"     toml:"servers,omitempty"`
 	Params  []Param  `json:"parameters,omitempty"  yaml:"parameters,omitempty"  toml:"parameters,omitempty"`
+	Exts    Anys     `json:"-" yaml:",inline" toml:"-"`
 }
 
 /*
-Sets the "op" at the given method. The method must be well-known, otherwise this
-will panic.
+Sets the "op" at the given method. Methods without dedicated fields, such as
+"PURGE" or "PROPFIND", are stored in `.AddOps`, which requires OpenAPI 3.2.
+The path item doesn't know the document's version; use `oas.Doc.TryRoute`,
+or the "op-version" rule of `oas.Lint`. Methods are case-sensitive. Panics if the method is
+not a valid HTTP token, or is a lowercase variant of a method with a
+dedicated field, such as "get".
 */
 func (self *Path) Method(meth string, op Op) *Path {
-	switch meth {
-	case http.MethodGet:
-		self.Get = &op
-	case http.MethodPut:
-		self.Put = &op
-	case http.MethodPost:
-		self.Post = &op
-	case http.MethodDelete:
-		self.Delete = &op
-	case http.MethodOptions:
-		self.Options = &op
-	case http.MethodHead:
-		self.Head = &op
-	case http.MethodPatch:
-		self.Patch = &op
-	case http.MethodTrace:
-		self.Trace = &op
-	default:
-		panic(fmt.Errorf(`[oas] unrecognized method %q`, meth))
+	ptr := self.opPtr(meth)
+	if ptr != nil {
+		*ptr = &op
+		return self
+	}
+
+	if !isMethodToken(meth) {
+		panic(fmt.Errorf(`[oas] invalid method %q`, meth))
 	}
+	if upper := strings.ToUpper(meth); self.opPtr(upper) != nil {
+		panic(fmt.Errorf(`[oas] unrecognized method %q; did you mean %q?`, meth, upper))
+	}
+	self.AddOps.Init()[meth] = &op
 	return self
 }
 
+/*
+Operations for methods without dedicated fields in `oas.Path`, keyed by
+method, called "additionalOperations" in OpenAPI 3.2. Reference:
+
+	https://spec.openapis.org/oas/v3.2.0#path-item-object
+*/
+type Ops map[string]*Op
+
+/*
+Inits the receiving variable or property to non-nil, returning the resulting
+mutable map. Handy for chaining.
+*/
+func (self *Ops) Init() Ops {
+	if *self == nil {
+		*self = Ops{}
+	}
+	return *self
+}
+
 // Short for "operation":
 // https://spec.openapis.org/oas/v3.1.0#operation-object
 type Op struct {
 	Ref       string    `json:"$ref,omitempty"        yaml:"$ref,omitempty"        toml:"$ref,omitempty"`
-	Sum       string    `json:"sum,omitempty"         yaml:"sum,omitempty"         toml:"sum,omitempty"`
+	Sum       string    `json:"summary,omitempty"     yaml:"summary,omitempty"     toml:"summary,omitempty"`
 	Desc      string    `json:"description,omitempty" yaml:"description,omitempty" toml:"description,omitempty"`
-	Tags      []Tag     `json:"tags,omitempty"         yaml:"tags,omitempty"         toml:"tags,omitempty"`
+	Tags      []string  `json:"tags,omitempty"         yaml:"tags,omitempty"         toml:"tags,omitempty"`
 	ExtDoc    *ExtDoc   `json:"externalDocs,omitempty" yaml:"externalDocs,omitempty" toml:"externalDocs,omitempty"`
 	OpId      string    `json:"operationId,omitempty"  yaml:"operationId,omitempty"  toml:"operationId,omitempty"`
 	Params    []Param   `json:"parameters,omitempty"   yaml:"parameters,omitempty"   toml:"parameters,omitempty"`
@@ -193,15 +270,20 @@ type Op struct {
 	Depr      bool      `json:"deprecated,omitempty"   yaml:"deprecated,omitempty"   toml:"deprecated,omitempty"`
 	Sec       []SecReq  `json:"security,omitempty"     yaml:"security,omitempty"     toml:"security,omitempty"`
 	Servers   []Server  `json:"servers,omitempty"      yaml:"servers,omitempty"      toml:"servers,omitempty"`
+	Exts      Anys      `json:"-" yaml:",inline" toml:"-"`
+
+	// Extensions of the "Responses" object, encoded inside `.Resps`.
+	RespsExts Anys `json:"-" yaml:"-" toml:"-"`
 }
 
 // Short for "external documentation":
 // https://spec.openapis.org/oas/v3.1.0#external-documentation-object
 type ExtDoc struct {
 	Ref  string `json:"$ref,omitempty"        yaml:"$ref,omitempty"        toml:"$ref,omitempty"`
-	Sum  string `json:"sum,omitempty"         yaml:"sum,omitempty"         toml:"sum,omitempty"`
+	Sum  string `json:"summary,omitempty"     yaml:"summary,omitempty"     toml:"summary,omitempty"`
 	Desc string `json:"description,omitempty" yaml:"description,omitempty" toml:"description,omitempty"`
 	Url  string `json:"url,omitempty"         yaml:"url,omitempty"         toml:"url,omitempty"`
+	Exts Anys   `json:"-" yaml:",inline" toml:"-"`
 }
 
 // Short for "parameter":
@@ -219,10 +301,11 @@ type Params map[string]Param
 // https://spec.openapis.org/oas/v3.1.0#request-body-object
 type Body struct {
 	Ref  string     `json:"$ref,omitempty"        yaml:"$ref,omitempty"        toml:"$ref,omitempty"`
-	Sum  string     `json:"sum,omitempty"         yaml:"sum,omitempty"         toml:"sum,omitempty"`
+	Sum  string     `json:"summary,omitempty"     yaml:"summary,omitempty"     toml:"summary,omitempty"`
 	Desc string     `json:"description,omitempty" yaml:"description,omitempty" toml:"description,omitempty"`
 	Cont MediaTypes `json:"content,omitempty"  yaml:"content,omitempty"  toml:"content,omitempty"`
 	Requ bool       `json:"required,omitempty" yaml:"required,omitempty" toml:"required,omitempty"`
+	Exts Anys       `json:"-" yaml:",inline" toml:"-"`
 }
 
 // Value method that returns a pointer. Sometimes useful as a shortcut.
@@ -234,12 +317,18 @@ type Bodies map[string]Body
 // https://spec.openapis.org/oas/v3.1.0#media-type-object
 type MediaType struct {
 	Ref      string    `json:"$ref,omitempty"        yaml:"$ref,omitempty"        toml:"$ref,omitempty"`
-	Sum      string    `json:"sum,omitempty"         yaml:"sum,omitempty"         toml:"sum,omitempty"`
+	Sum      string    `json:"summary,omitempty"     yaml:"summary,omitempty"     toml:"summary,omitempty"`
 	Desc     string    `json:"description,omitempty" yaml:"description,omitempty" toml:"description,omitempty"`
 	Schema   Schema    `json:"schema,omitempty"   yaml:"schema,omitempty"   toml:"schema,omitempty"`
 	Example  any       `json:"example,omitempty"  yaml:"example,omitempty"  toml:"example,omitempty"`
 	Examples Examples  `json:"examples,omitempty" yaml:"examples,omitempty" toml:"examples,omitempty"`
 	Encoding Encodings `json:"encoding,omitempty" yaml:"encoding,omitempty" toml:"encoding,omitempty"`
+
+	// Schema of each item of a sequential media type such as NDJSON. OAS 3.2.
+	// https://spec.openapis.org/oas/v3.2.0#media-type-object
+	ItemSchema *Schema `json:"itemSchema,omitempty" yaml:"itemSchema,omitempty" toml:"itemSchema,omitempty"`
+
+	Exts Anys `json:"-" yaml:",inline" toml:"-"`
 }
 
 // https://spec.openapis.org/oas/v3.1.0#media-type-object
@@ -248,35 +337,61 @@ type MediaTypes map[string]MediaType
 // https://spec.openapis.org/oas/v3.1.0#encoding-object
 type Encoding struct {
 	Ref      string `json:"$ref,omitempty"        yaml:"$ref,omitempty"        toml:"$ref,omitempty"`
-	Sum      string `json:"sum,omitempty"         yaml:"sum,omitempty"         toml:"sum,omitempty"`
+	Sum      string `json:"summary,omitempty"     yaml:"summary,omitempty"     toml:"summary,omitempty"`
 	Desc     string `json:"description,omitempty" yaml:"description,omitempty" toml:"description,omitempty"`
 	ConType  string `json:"contentType,omitempty"   yaml:"contentType,omitempty"   toml:"contentType,omitempty"`
 	Head     Heads  `json:"headers,omitempty"       yaml:"headers,omitempty"       toml:"headers,omitempty"`
 	Style    string `json:"style,omitempty"         yaml:"style,omitempty"         toml:"style,omitempty"`
-	Explode  bool   `json:"explode,omitempty"       yaml:"explode,omitempty"       toml:"explode,omitempty"`
+	Explode  *bool  `json:"explode,omitempty"       yaml:"explode,omitempty"       toml:"explode,omitempty"`
 	Reserved bool   `json:"allowReserved,omitempty" yaml:"allowReserved,omitempty" toml:"allowReserved,omitempty"`
+	Exts     Anys   `json:"-" yaml:",inline" toml:"-"`
 }
 
 // https://spec.openapis.org/oas/v3.1.0#encoding-object
 type Encodings map[string]Encoding
 
+/*
+Inits the receiving variable or property to non-nil, returning the resulting
+mutable map. Handy for chaining.
+*/
+func (self *Encodings) Init() Encodings {
+	if *self == nil {
+		*self = Encodings{}
+	}
+	return *self
+}
+
 // Short for "response":
 // https://spec.openapis.org/oas/v3.1.0#response-object
 type Resp struct {
 	Ref   string     `json:"$ref,omitempty"        yaml:"$ref,omitempty"        toml:"$ref,omitempty"`
-	Sum   string     `json:"sum,omitempty"         yaml:"sum,omitempty"         toml:"sum,omitempty"`
+	Sum   string     `json:"summary,omitempty"     yaml:"summary,omitempty"     toml:"summary,omitempty"`
 	Desc  string     `json:"description,omitempty" yaml:"description,omitempty" toml:"description,omitempty"`
 	Head  Heads      `json:"headers,omitempty"     yaml:"headers,omitempty"     toml:"headers,omitempty"`
 	Cont  MediaTypes `json:"content,omitempty"     yaml:"content,omitempty"     toml:"content,omitempty"`
 	Links Links      `json:"links,omitempty"       yaml:"links,omitempty"       toml:"links,omitempty"`
+	Exts  Anys       `json:"-" yaml:",inline" toml:"-"`
 }
 
 // https://spec.openapis.org/oas/v3.1.0#responses-object
 type Resps map[string]Resp
 
-// https://spec.openapis.org/oas/v3.1.0#callback-object.
-// May also be `{"$ref": "..."}`.
-type Callback map[string]string
+/*
+Reference:
+
+	https://spec.openapis.org/oas/v3.1.0#callback-object
+
+The spec defines callbacks as maps of runtime expressions to path items, which
+are stored in `.Paths`, and encoded inline. A callback may also be a reference,
+in which case `.Ref` is set and `.Paths` is normally empty.
+*/
+type Callback struct {
+	Ref   string `json:"$ref,omitempty"        yaml:"$ref,omitempty"        toml:"$ref,omitempty"`
+	Sum   string `json:"summary,omitempty"     yaml:"summary,omitempty"     toml:"summary,omitempty"`
+	Desc  string `json:"description,omitempty" yaml:"description,omitempty" toml:"description,omitempty"`
+	Paths Paths  `json:"-" yaml:",inline" toml:"-"`
+	Exts  Anys   `json:"-" yaml:",inline" toml:"-"`
+}
 
 // https://spec.openapis.org/oas/v3.1.0#callback-object
 type Callbacks map[string]Callback
@@ -284,10 +399,11 @@ type Callbacks map[string]Callback
 // https://spec.openapis.org/oas/v3.1.0#example-object
 type Example struct {
 	Ref   string `json:"$ref,omitempty"        yaml:"$ref,omitempty"        toml:"$ref,omitempty"`
-	Sum   string `json:"sum,omitempty"         yaml:"sum,omitempty"         toml:"sum,omitempty"`
+	Sum   string `json:"summary,omitempty"     yaml:"summary,omitempty"     toml:"summary,omitempty"`
 	Desc  string `json:"description,omitempty" yaml:"description,omitempty" toml:"description,omitempty"`
-	Val   string `json:"value,omitempty"         yaml:"value,omitempty"         toml:"value,omitempty"`
+	Val   any    `json:"value,omitempty"         yaml:"value,omitempty"         toml:"value,omitempty"`
 	ExVal string `json:"externalValue,omitempty" yaml:"externalValue,omitempty" toml:"externalValue,omitempty"`
+	Exts  Anys   `json:"-" yaml:",inline" toml:"-"`
 }
 
 // https://spec.openapis.org/oas/v3.1.0#example-object
@@ -296,13 +412,14 @@ type Examples map[string]Example
 // https://spec.openapis.org/oas/v3.1.0#link-object
 type Link struct {
 	Ref     string  `json:"$ref,omitempty"        yaml:"$ref,omitempty"        toml:"$ref,omitempty"`
-	Sum     string  `json:"sum,omitempty"         yaml:"sum,omitempty"         toml:"sum,omitempty"`
+	Sum     string  `json:"summary,omitempty"     yaml:"summary,omitempty"     toml:"summary,omitempty"`
 	Desc    string  `json:"description,omitempty" yaml:"description,omitempty" toml:"description,omitempty"`
 	OpRef   string  `json:"operationRef,omitempty" yaml:"operationRef,omitempty" toml:"operationRef,omitempty"`
 	OpId    string  `json:"operationId,omitempty"  yaml:"operationId,omitempty"  toml:"operationId,omitempty"`
 	Params  Anys    `json:"parameters,omitempty"   yaml:"parameters,omitempty"   toml:"parameters,omitempty"`
 	ReqBody any     `json:"requestBody,omitempty"  yaml:"requestBody,omitempty"  toml:"requestBody,omitempty"`
 	Server  *Server `json:"server,omitempty"       yaml:"server,omitempty"       toml:"server,omitempty"`
+	Exts    Anys    `json:"-" yaml:",inline" toml:"-"`
 }
 
 // https://spec.openapis.org/oas/v3.1.0#link-object
@@ -312,18 +429,19 @@ type Links map[string]Link
 // https://spec.openapis.org/oas/v3.1.0#header-object
 type Head struct {
 	Ref      string     `json:"$ref,omitempty"        yaml:"$ref,omitempty"        toml:"$ref,omitempty"`
-	Sum      string     `json:"sum,omitempty"         yaml:"sum,omitempty"         toml:"sum,omitempty"`
+	Sum      string     `json:"summary,omitempty"     yaml:"summary,omitempty"     toml:"summary,omitempty"`
 	Desc     string     `json:"description,omitempty" yaml:"description,omitempty" toml:"description,omitempty"`
 	Requ     bool       `json:"required,omitempty"        yaml:"required,omitempty"        toml:"required,omitempty"`
 	Depr     bool       `json:"deprecated,omitempty"      yaml:"deprecated,omitempty"      toml:"deprecated,omitempty"`
 	Empty    bool       `json:"allowEmptyValue,omitempty" yaml:"allowEmptyValue,omitempty" toml:"allowEmptyValue,omitempty"`
 	Style    string     `json:"style,omitempty"           yaml:"style,omitempty"           toml:"style,omitempty"`
-	Explode  bool       `json:"explode,omitempty"         yaml:"explode,omitempty"         toml:"explode,omitempty"`
+	Explode  *bool      `json:"explode,omitempty"         yaml:"explode,omitempty"         toml:"explode,omitempty"`
 	Reserved bool       `json:"allowReserved,omitempty"   yaml:"allowReserved,omitempty"   toml:"allowReserved,omitempty"`
 	Schema   *Schema    `json:"schema,omitempty"          yaml:"schema,omitempty"          toml:"schema,omitempty"`
 	Example  any        `json:"example,omitempty"         yaml:"example,omitempty"         toml:"example,omitempty"`
 	Examples Examples   `json:"examples,omitempty"        yaml:"examples,omitempty"        toml:"examples,omitempty"`
 	Cont     MediaTypes `json:"content,omitempty"         yaml:"content,omitempty"         toml:"content,omitempty"`
+	Exts     Anys       `json:"-" yaml:",inline" toml:"-"`
 }
 
 // https://spec.openapis.org/oas/v3.1.0#header-object
@@ -332,39 +450,42 @@ type Heads map[string]Head
 // https://spec.openapis.org/oas/v3.1.0#tag-object
 type Tag struct {
 	Ref    string  `json:"$ref,omitempty"        yaml:"$ref,omitempty"        toml:"$ref,omitempty"`
-	Sum    string  `json:"sum,omitempty"         yaml:"sum,omitempty"         toml:"sum,omitempty"`
+	Sum    string  `json:"summary,omitempty"     yaml:"summary,omitempty"     toml:"summary,omitempty"`
 	Desc   string  `json:"description,omitempty" yaml:"description,omitempty" toml:"description,omitempty"`
 	Name   string  `json:"name,omitempty"         yaml:"name,omitempty"         toml:"name,omitempty"`
 	ExtDoc *ExtDoc `json:"externalDocs,omitempty" yaml:"externalDocs,omitempty" toml:"externalDocs,omitempty"`
+	Exts   Anys    `json:"-" yaml:",inline" toml:"-"`
 }
 
 // Short for "discriminator":
 // https://spec.openapis.org/oas/v3.1.0#discriminator-object
 type Discr struct {
 	Ref  string            `json:"$ref,omitempty"        yaml:"$ref,omitempty"        toml:"$ref,omitempty"`
-	Sum  string            `json:"sum,omitempty"         yaml:"sum,omitempty"         toml:"sum,omitempty"`
+	Sum  string            `json:"summary,omitempty"     yaml:"summary,omitempty"     toml:"summary,omitempty"`
 	Desc string            `json:"description,omitempty" yaml:"description,omitempty" toml:"description,omitempty"`
 	Prop string            `json:"propertyName,omitempty" yaml:"propertyName,omitempty" toml:"propertyName,omitempty"`
 	Map  map[string]string `json:"mapping,omitempty"      yaml:"mapping,omitempty"      toml:"mapping,omitempty"`
+	Exts Anys              `json:"-" yaml:",inline" toml:"-"`
 }
 
 // https://spec.openapis.org/oas/v3.1.0#xml-object
 type Xml struct {
 	Ref    string `json:"$ref,omitempty"        yaml:"$ref,omitempty"        toml:"$ref,omitempty"`
-	Sum    string `json:"sum,omitempty"         yaml:"sum,omitempty"         toml:"sum,omitempty"`
+	Sum    string `json:"summary,omitempty"     yaml:"summary,omitempty"     toml:"summary,omitempty"`
 	Desc   string `json:"description,omitempty" yaml:"description,omitempty" toml:"description,omitempty"`
 	Name   string `json:"name,omitempty"      yaml:"name,omitempty"      toml:"name,omitempty"`
 	Nspace string `json:"namespace,omitempty" yaml:"namespace,omitempty" toml:"namespace,omitempty"`
 	Prefix string `json:"prefix,omitempty"    yaml:"prefix,omitempty"    toml:"prefix,omitempty"`
 	Attr   bool   `json:"attribute,omitempty" yaml:"attribute,omitempty" toml:"attribute,omitempty"`
 	Wrap   bool   `json:"wrapped,omitempty"   yaml:"wrapped,omitempty"   toml:"wrapped,omitempty"`
+	Exts   Anys   `json:"-" yaml:",inline" toml:"-"`
 }
 
 // Short for "security scheme".
 // https://spec.openapis.org/oas/v3.1.0#security-scheme-object
 type SecScheme struct {
 	Ref        string `json:"$ref,omitempty"        yaml:"$ref,omitempty"        toml:"$ref,omitempty"`
-	Sum        string `json:"sum,omitempty"         yaml:"sum,omitempty"         toml:"sum,omitempty"`
+	Sum        string `json:"summary,omitempty"     yaml:"summary,omitempty"     toml:"summary,omitempty"`
 	Desc       string `json:"description,omitempty" yaml:"description,omitempty" toml:"description,omitempty"`
 	Type       string `json:"type,omitempty"             yaml:"type,omitempty"             toml:"type,omitempty"`
 	Name       string `json:"name,omitempty"             yaml:"name,omitempty"             toml:"name,omitempty"`
@@ -373,6 +494,7 @@ type SecScheme struct {
 	BearFormat string `json:"bearerFormat,omitempty"     yaml:"bearerFormat,omitempty"     toml:"bearerFormat,omitempty"`
 	Flows      *Flows `json:"flows,omitempty"            yaml:"flows,omitempty"            toml:"flows,omitempty"`
 	OidUrl     string `json:"openIdConnectUrl,omitempty" yaml:"openIdConnectUrl,omitempty" toml:"openIdConnectUrl,omitempty"`
+	Exts       Anys   `json:"-" yaml:",inline" toml:"-"`
 }
 
 // https://spec.openapis.org/oas/v3.1.0#security-scheme-object
@@ -381,23 +503,25 @@ type SecSchemes map[string]SecScheme
 // https://spec.openapis.org/oas/v3.1.0#oauth-flows-object
 type Flows struct {
 	Ref        string `json:"$ref,omitempty"        yaml:"$ref,omitempty"        toml:"$ref,omitempty"`
-	Sum        string `json:"sum,omitempty"         yaml:"sum,omitempty"         toml:"sum,omitempty"`
+	Sum        string `json:"summary,omitempty"     yaml:"summary,omitempty"     toml:"summary,omitempty"`
 	Desc       string `json:"description,omitempty" yaml:"description,omitempty" toml:"description,omitempty"`
 	Implicit   Flow   `json:"implicit,omitempty"          yaml:"implicit,omitempty"          toml:"implicit,omitempty"`
 	Password   Flow   `json:"password,omitempty"          yaml:"password,omitempty"          toml:"password,omitempty"`
 	ClientCred Flow   `json:"clientCredentials,omitempty" yaml:"clientCredentials,omitempty" toml:"clientCredentials,omitempty"`
 	AuthCode   Flow   `json:"authorizationCode,omitempty" yaml:"authorizationCode,omitempty" toml:"authorizationCode,omitempty"`
+	Exts       Anys   `json:"-" yaml:",inline" toml:"-"`
 }
 
 // https://spec.openapis.org/oas/v3.1.0#oauth-flow-object
 type Flow struct {
 	Ref        string            `json:"$ref,omitempty"        yaml:"$ref,omitempty"        toml:"$ref,omitempty"`
-	Sum        string            `json:"sum,omitempty"         yaml:"sum,omitempty"         toml:"sum,omitempty"`
+	Sum        string            `json:"summary,omitempty"     yaml:"summary,omitempty"     toml:"summary,omitempty"`
 	Desc       string            `json:"description,omitempty" yaml:"description,omitempty" toml:"description,omitempty"`
 	AuthUrl    string            `json:"authorizationUrl,omitempty" yaml:"authorizationUrl,omitempty"  toml:"authorizationUrl,omitempty"`
 	TokenUrl   string            `json:"tokenUrl,omitempty"         yaml:"tokenUrl,omitempty"          toml:"tokenUrl,omitempty"`
 	RefreshUrl string            `json:"refreshUrl,omitempty"       yaml:"refreshUrl,omitempty"        toml:"refreshUrl,omitempty"`
-	Scopes     map[string]string `json:"scopes,omitempty"           yaml:"scopes,omitempty"            toml:"scopes,omitempty"`
+	Scopes     map[string]string `json:"scopes"                     yaml:"scopes"                      toml:"scopes"`
+	Exts       Anys              `json:"-" yaml:",inline" toml:"-"`
 }
 
 // Short for "secutity requirement".
@@ -0,0 +1,164 @@
+package oas
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// Methods which have dedicated fields in `oas.Path`, in field order.
+var pathMethods = [...]string{
+	http.MethodGet,
+	http.MethodPut,
+	http.MethodPost,
+	http.MethodDelete,
+	http.MethodOptions,
+	http.MethodHead,
+	http.MethodPatch,
+	http.MethodTrace,
+	MethodQuery,
+}
+
+// Returns a pointer to the op field for the given method, or nil if unknown.
+func (self *Path) opPtr(meth string) **Op {
+	switch meth {
+	case http.MethodGet:
+		return &self.Get
+	case http.MethodPut:
+		return &self.Put
+	case http.MethodPost:
+		return &self.Post
+	case http.MethodDelete:
+		return &self.Delete
+	case http.MethodOptions:
+		return &self.Options
+	case http.MethodHead:
+		return &self.Head
+	case http.MethodPatch:
+		return &self.Patch
+	case http.MethodTrace:
+		return &self.Trace
+	case MethodQuery:
+		return &self.Query
+	default:
+		return nil
+	}
+}
+
+func (self Paths) routeDup(path, meth string) error {
+	if self[path].Op(meth) != nil {
+		return fmt.Errorf(`[oas] duplicate route %v %v`, meth, path)
+	}
+
+	// Without parameters, the path can only be identical to itself.
+	if !strings.Contains(path, `{`) {
+		return nil
+	}
+
+	norm := pathNorm(path)
+	for key := range self {
+		if key != path && strings.Contains(key, `{`) && pathNorm(key) == norm {
+			return fmt.Errorf(`[oas] path %q is identical to registered path %q, differing only in parameter names`, path, key)
+		}
+	}
+	return nil
+}
+
+// Replaces parameter names with "{}": "/users/{id}" -> "/users/{}".
+func pathNorm(path string) string { return pathParam.ReplaceAllLiteralString(path, `{}`) }
+
+/*
+Sets or deletes the op at the given method. Unlike `oas.Path.Method`, doesn't
+validate the method.
+*/
+func (self *Path) setOp(meth string, op *Op) {
+	ptr := self.opPtr(meth)
+	if ptr != nil {
+		*ptr = op
+		return
+	}
+	if op != nil {
+		self.AddOps.Init()[meth] = op
+		return
+	}
+	delete(self.AddOps, meth)
+	if len(self.AddOps) == 0 {
+		self.AddOps = nil
+	}
+}
+
+/*
+Methods of the given paths which have operations, without duplicates: methods
+with dedicated fields in field order, then additional ones, sorted.
+*/
+func pathMethodsOf(src ...Path) (out []string) {
+	var add []string
+	for _, meth := range pathMethods {
+		for _, path := range src {
+			if path.Op(meth) != nil {
+				out = append(out, meth)
+				break
+			}
+		}
+	}
+	for _, path := range src {
+		for meth := range path.AddOps {
+			if !stringsContain(add, meth) {
+				add = append(add, meth)
+			}
+		}
+	}
+	sort.Strings(add)
+	return append(out, add...)
+}
+
+// JSON Pointer to the op at the given method of the path item at the given pointer.
+func pathOpPtr(ptr, meth string) string {
+	if isPathMethod(meth) {
+		return ptrAppend(ptr, strings.ToLower(meth))
+	}
+	return ptrAppend(ptrAppend(ptr, `additionalOperations`), meth)
+}
+
+func isPathMethod(meth string) bool {
+	var path Path
+	return path.opPtr(meth) != nil
+}
+
+// Methods describable without OpenAPI 3.2.
+func isPathMethod31(meth string) bool {
+	return isPathMethod(meth) && meth != MethodQuery
+}
+
+func (self *Doc) isVer32() bool { return strings.HasPrefix(self.Openapi, `3.2.`) }
+
+/*
+False if the method requires OpenAPI 3.2, such as "QUERY" or any method stored
+in `oas.Path.AddOps`, while the document targets an earlier version.
+`oas.Paths` and `oas.Path` don't know the version, so this is checked by
+`oas.Doc.TryRoute` for new routes, and by the "op-version" lint rule for the
+entire document.
+*/
+func (self *Doc) methodAllowed(meth string) bool {
+	return isPathMethod31(meth) || self.Openapi == `` || self.isVer32()
+}
+
+func (self *Doc) methodVerMsg(meth string) string {
+	return fmt.Sprintf(`method %q requires OpenAPI %v, but the document targets %v`, meth, Ver32, self.Openapi)
+}
+
+// Reference: https://www.rfc-editor.org/rfc/rfc9110#name-tokens
+func isMethodToken(val string) bool {
+	if val == `` {
+		return false
+	}
+	for ind := 0; ind < len(val); ind++ {
+		char := val[ind]
+		if !(char >= 'a' && char <= 'z' || char >= 'A' && char <= 'Z' || char >= '0' && char <= '9' ||
+			strings.IndexByte("!#$%&'*+-.^_`|~", char) >= 0) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,142 @@
+package oas
+
+/*
+Fluent builder for operations, created via `oas.Doc.Operation`. Every
+operation gets a stable "operationId", unique in the document: either the
+given one, or derived from the handler function (see `oas.OpBuilder.Func`),
+or from the method and path, such as "getUsersById" for "GET /users/{id}".
+Unless specified, the summary is derived from the "operationId", such as
+"Get users by id", rather than defaulting to the path like `oas.Paths.Route`.
+Example:
+
+	doc.Operation(`/persons/{id}`, http.MethodGet).
+		Func(srv.GetPerson).
+		Tags(`persons`).
+		PathParam(`id`, ``, `Person id.`).
+		Resps(doc.Responses().Ok(Person{}, ``).Done()).
+		Done()
+*/
+type OpBuilder struct {
+	doc  *Doc
+	path string
+	meth string
+	fun  any
+	out  Op
+}
+
+// Starts building an operation for the given path and method. See `oas.OpBuilder`.
+func (self *Doc) Operation(path, meth string) *OpBuilder {
+	return &OpBuilder{doc: self, path: path, meth: meth}
+}
+
+// Sets "operationId", overriding the derived one.
+func (self *OpBuilder) Id(val string) *OpBuilder {
+	self.out.OpId = val
+	return self
+}
+
+/*
+Derives "operationId" from the name of the given handler function, as reported
+by `runtime.FuncForPC`, without the package path and receiver type. For
+example, both `api.GetPerson` and the method value `srv.GetPerson` result in
+"GetPerson". Ignored if `oas.OpBuilder.Id` is used. When building, panics if
+the input is not a function, or is an anonymous function.
+*/
+func (self *OpBuilder) Func(val any) *OpBuilder {
+	self.fun = val
+	return self
+}
+
+// Sets the summary, overriding the derived one.
+func (self *OpBuilder) Sum(val string) *OpBuilder {
+	self.out.Sum = val
+	return self
+}
+
+// Sets the description.
+func (self *OpBuilder) Desc(val string) *OpBuilder {
+	self.out.Desc = val
+	return self
+}
+
+// Appends the given tags.
+func (self *OpBuilder) Tags(vals ...string) *OpBuilder {
+	self.out.Tags = append(self.out.Tags, vals...)
+	return self
+}
+
+// Appends the given parameters.
+func (self *OpBuilder) Param(vals ...Param) *OpBuilder {
+	self.out.Params = append(self.out.Params, vals...)
+	return self
+}
+
+/*
+Appends a required path parameter with the schema of the given type, after
+registering it in the document. The input is used only as a type carrier.
+*/
+func (self *OpBuilder) PathParam(name string, typ any, desc string) *OpBuilder {
+	return self.Param(self.param(InPath, name, typ, desc, true))
+}
+
+// Appends an optional query parameter. See `oas.OpBuilder.PathParam`.
+func (self *OpBuilder) Query(name string, typ any, desc string) *OpBuilder {
+	return self.Param(self.param(InQuery, name, typ, desc, false))
+}
+
+// Appends an optional header parameter. See `oas.OpBuilder.PathParam`.
+func (self *OpBuilder) Header(name string, typ any, desc string) *OpBuilder {
+	return self.Param(self.param(InHeader, name, typ, desc, false))
+}
+
+// Sets the request body.
+func (self *OpBuilder) Body(val Body) *OpBuilder {
+	self.out.ReqBody = &val
+	return self
+}
+
+// Sets a JSON request body of the given type. See `oas.Doc.JsonBody`.
+func (self *OpBuilder) Json(typ any) *OpBuilder {
+	return self.Body(self.doc.JsonBody(typ))
+}
+
+// Sets the responses, typically built via `oas.Doc.Responses`.
+func (self *OpBuilder) Resps(val Resps) *OpBuilder {
+	self.out.Resps = val
+	return self
+}
+
+// Appends the given security requirements.
+func (self *OpBuilder) Sec(vals ...SecReq) *OpBuilder {
+	self.out.Sec = append(self.out.Sec, vals...)
+	return self
+}
+
+// Marks the operation as deprecated.
+func (self *OpBuilder) Depr() *OpBuilder {
+	self.out.Depr = true
+	return self
+}
+
+/*
+Registers the operation via `oas.Doc.Route`, and returns the document for
+chaining. Panics if the route is a duplicate, or if the "operationId" is
+already used by another operation in the document.
+*/
+func (self *OpBuilder) Done() *Doc {
+	op := self.out
+
+	if op.OpId == `` {
+		if self.fun != nil {
+			op.OpId = opIdFunc(self.fun)
+		} else {
+			op.OpId = opIdPath(self.meth, self.path)
+		}
+	}
+	if op.Sum == `` {
+		op.Sum = opSum(op.OpId)
+	}
+
+	self.doc.opIdUnique(self.path, self.meth, op.OpId)
+	return self.doc.Route(self.path, self.meth, op)
+}
@@ -0,0 +1,117 @@
+package oas
+
+import (
+	"fmt"
+	r "reflect"
+	"runtime"
+	"strings"
+	"unicode"
+)
+
+func (self *OpBuilder) param(in, name string, typ any, desc string, requ bool) Param {
+	return Param{
+		Head: Head{Desc: desc, Requ: requ, Schema: self.doc.Sch(typ).Opt()},
+		Name: name,
+		In:   in,
+	}
+}
+
+/*
+The operation already registered at the same path and method is skipped, to
+let `oas.Doc.Route` report the duplicate route instead.
+*/
+func (self *Doc) opIdUnique(path, meth, id string) {
+	same := self.Paths[path].Op(meth)
+	lintOps(self, func(ptr, _ string, op *Op, _ *Path) {
+		if op.OpId == id && op != same {
+			panic(fmt.Errorf(`[oas] operationId %q of %v %v is already used at %q`, id, meth, path, ptr))
+		}
+	})
+}
+
+// Name of a function without the package path, receiver type and suffixes.
+func opIdFunc(val any) string {
+	fun := r.ValueOf(val)
+	if fun.Kind() != r.Func || fun.IsNil() {
+		panic(fmt.Errorf(`[oas] unable to derive operationId from non-function %T`, val))
+	}
+
+	full := runtime.FuncForPC(fun.Pointer()).Name()
+	name := strings.ReplaceAll(strings.TrimSuffix(full, `-fm`), `[...]`, ``)
+	name = name[strings.LastIndexByte(name, '/')+1:]
+
+	ind := strings.LastIndexByte(name, '.')
+	name = name[ind+1:]
+	if ind < 0 || !opIsIdent(name) {
+		panic(fmt.Errorf(`[oas] unable to derive operationId from anonymous function %q`, full))
+	}
+	return name
+}
+
+// Closures are named like "func1" and "glob..func1".
+func opIsIdent(val string) bool {
+	if val == `` || unicode.IsDigit(rune(val[0])) {
+		return false
+	}
+	return !(strings.HasPrefix(val, `func`) && strings.Trim(val[len(`func`):], `0123456789`) == ``)
+}
+
+/*
+Camel case of the lowercase method and path segments, with parameters
+prefixed by "By": "GET /users/{id}" -> "getUsersById".
+*/
+func opIdPath(meth, path string) string {
+	var buf strings.Builder
+	buf.WriteString(strings.ToLower(meth))
+
+	for _, seg := range strings.Split(path, `/`) {
+		if strings.HasPrefix(seg, `{`) && strings.HasSuffix(seg, `}`) {
+			buf.WriteString(`By`)
+			seg = seg[1 : len(seg)-1]
+		}
+
+		for _, word := range strings.FieldsFunc(seg, opIsSep) {
+			buf.WriteString(strings.ToUpper(word[:1]))
+			buf.WriteString(word[1:])
+		}
+	}
+	return buf.String()
+}
+
+func opIsSep(val rune) bool {
+	return !(val < unicode.MaxASCII && (unicode.IsLetter(val) || unicode.IsDigit(val)))
+}
+
+/*
+Sentence from the words of a camel case identifier. Acronyms are preserved:
+"getHTTPStatusById" -> "Get HTTP status by id".
+*/
+func opSum(id string) string {
+	words := opWords(id)
+	for ind, word := range words {
+		if ind == 0 {
+			words[ind] = strings.ToUpper(word[:1]) + word[1:]
+		} else if strings.ToUpper(word) != word {
+			words[ind] = strings.ToLower(word)
+		}
+	}
+	return strings.Join(words, ` `)
+}
+
+func opWords(src string) (out []string) {
+	for _, part := range strings.FieldsFunc(src, opIsSep) {
+		var start int
+		for ind := 1; ind < len(part); ind++ {
+			prev, next := rune(part[ind-1]), rune(part[ind])
+			acro := unicode.IsUpper(prev) && unicode.IsUpper(next) &&
+				ind+1 < len(part) && unicode.IsLower(rune(part[ind+1]))
+
+			if (!unicode.IsUpper(prev) && unicode.IsUpper(next)) || acro {
+				out = append(out, part[start:ind])
+				start = ind
+			}
+		}
+		out = append(out, part[start:])
+	}
+	return
+}
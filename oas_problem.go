@@ -0,0 +1,74 @@
+package oas
+
+/*
+Name of the schema and response components registered by the problem details
+helpers, such as `oas.Doc.ProblemSchema`.
+*/
+const ProblemName = `Problem`
+
+/*
+Returns a reference to the schema of RFC 9457 problem details, registering the
+schema in `.Comps.Schemas` under `oas.ProblemName` if missing. The schema
+allows extension members. Reference:
+
+	https://www.rfc-editor.org/rfc/rfc9457#section-3
+*/
+func (self *Doc) ProblemSchema() Schema {
+	if _, ok := self.Comps.Schemas[ProblemName]; !ok {
+		self.Comps.Schemas.Init()[ProblemName] = problemSchema()
+	}
+	return RefSchema(ProblemName)
+}
+
+/*
+Returns a response with a body of type "application/problem+json", described
+by `oas.Doc.ProblemSchema`. If `ext` is non-nil, it's used as a type carrier
+for extension members: the schema of its type is combined with the problem
+schema via "allOf". If the description is empty, uses a generic one.
+*/
+func (self *Doc) ProblemResp(ext any, desc string) Resp {
+	if desc == `` {
+		desc = `Problem details.`
+	}
+
+	sch := self.ProblemSchema()
+	if ext != nil {
+		sch = Schema{AllOf: []Schema{sch, self.Sch(ext)}}
+	}
+	return Resp{Desc: desc, Cont: MediaTypes{ConTypeProblem: {Schema: sch}}}
+}
+
+/*
+Adds a problem details response for the given status. See
+`oas.Doc.ProblemResp`. If the description is empty, uses the default for the
+status.
+*/
+func (self *RespsBuilder) Problem(status string, ext any, desc string) *RespsBuilder {
+	if desc == `` {
+		desc = RespDesc(status)
+	}
+	return self.Status(status, self.doc.ProblemResp(ext, desc))
+}
+
+/*
+Adds a "default" response referencing the problem details response component
+to every operation in the document which doesn't have a "default" response.
+The component is registered in `.Comps.Resps` under `oas.ProblemName`; see
+`oas.Doc.ProblemResp` for the meaning of `ext`. Operations without any
+responses are skipped, because they're incomplete and would otherwise appear
+to only fail.
+*/
+func (self *Doc) DefaultProblems(ext any) {
+	self.compResp(ProblemName, self.ProblemResp(ext, `Unexpected error.`))
+
+	_ = self.Walk(func(_ string, val any) error {
+		op, _ := val.(*Op)
+		if op == nil || len(op.Resps) == 0 {
+			return nil
+		}
+		if _, ok := op.Resps[`default`]; !ok {
+			op.Resps[`default`] = Resp{Ref: RespRef(ProblemName)}
+		}
+		return nil
+	})
+}
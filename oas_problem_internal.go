@@ -0,0 +1,30 @@
+package oas
+
+func problemSchema() Schema {
+	uriRef := func(desc string) Schema {
+		return Schema{Type: []string{TypeStr}, Format: `uri-reference`, Desc: desc}
+	}
+	str := func(desc string) Schema {
+		return Schema{Type: []string{TypeStr}, Desc: desc}
+	}
+
+	min, max := 100.0, 599.0
+
+	return Schema{
+		Title: ProblemName,
+		Desc:  `Problem details as defined by RFC 9457. May have additional extension members.`,
+		Type:  []string{TypeObj},
+		Props: Schemas{
+			`type`:     withDefault(uriRef(`URI reference identifying the problem type.`), `about:blank`),
+			`title`:    str(`Short, human-readable summary of the problem type.`),
+			`status`:   {Type: []string{TypeInt}, Format: FormatInt32, Min: &min, Max: &max, Desc: `HTTP status code.`},
+			`detail`:   str(`Human-readable explanation specific to this occurrence of the problem.`),
+			`instance`: uriRef(`URI reference identifying this occurrence of the problem.`),
+		},
+	}
+}
+
+func withDefault(sch Schema, val any) Schema {
+	sch.Default = val
+	return sch
+}
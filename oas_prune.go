@@ -0,0 +1,27 @@
+package oas
+
+/*
+Returns the set of components reachable from the paths, webhooks and other
+parts of the document outside of `.Comps`, following references transitively,
+including references between components. Components are identified by local
+references such as `#/components/schemas/User`. Security schemes are reachable
+via security requirements.
+*/
+func (self Doc) Reachable() map[string]bool { return compsReachable(&self) }
+
+/*
+Deletes unreachable components, as defined by `oas.Doc.Reachable`, from all
+component maps except `.Comps.SecSchemes`. Security schemes are often declared
+without being required globally or by any operation, and are kept. Returns the
+sorted references of the deleted components.
+
+Useful for removing intermediary schemas which were generated by `oas.Doc.Sch`
+but are no longer used after the user replaced them.
+*/
+func (self *Doc) Prune() []string {
+	out := compsUnreachable(self)
+	for _, ref := range out {
+		compsDelete(&self.Comps, ref)
+	}
+	return out
+}
@@ -0,0 +1,84 @@
+package oas
+
+import (
+	"fmt"
+	"net/url"
+	r "reflect"
+)
+
+/*
+Resolves the given JSON Pointer against the document, without following
+references. The pointer must be empty (the document itself) or start with "/".
+"~0" and "~1" in pointer tokens are unescaped as "~" and "/". Supports paths
+into any part of the document, including extensions and values of "any" type
+such as examples. Returns a copy of the found value, with pointers
+dereferenced: for example `/paths/~1users/get` returns `oas.Op`, not `*oas.Op`.
+Unset optional fields are considered missing.
+*/
+func (self *Doc) Pointer(ptr string) (any, error) {
+	val, err := ptrResolve(r.ValueOf(self).Elem(), ptr)
+	if err != nil {
+		return nil, err
+	}
+	return val.Interface(), nil
+}
+
+/*
+Resolves a local reference, such as `#/components/responses/NotFound` or
+`#/paths/~1users/get`, via `oas.Doc.Pointer`, then follows further references
+if the found object has `.Ref`, returning the first non-reference. The
+fragment may be percent-encoded, as allowed by RFC 6901. Returns an error for
+non-local references, missing targets, and reference cycles.
+*/
+func (self *Doc) Resolve(ref string) (any, error) {
+	var seen []string
+
+	for {
+		for _, prev := range seen {
+			if prev == ref {
+				return nil, fmt.Errorf(`[oas] reference cycle: %q`, append(seen, ref))
+			}
+		}
+		seen = append(seen, ref)
+
+		frag, ok := unprefix(ref, `#`)
+		if !ok {
+			return nil, fmt.Errorf(`[oas] unable to resolve %q: only local references are supported`, ref)
+		}
+
+		ptr, err := url.PathUnescape(frag)
+		if err != nil {
+			ptr = frag
+		}
+
+		val, err := self.Pointer(ptr)
+		if err != nil {
+			return nil, err
+		}
+
+		next := refOf(val)
+		if next == `` {
+			return val, nil
+		}
+		ref = next
+	}
+}
+
+/*
+Typed version of `oas.Doc.Resolve`. Returns an error if the resolved value is
+not of the given type. Example:
+
+	resp, err := oas.ResolveAs[oas.Resp](&doc, `#/components/responses/NotFound`)
+*/
+func ResolveAs[A any](doc *Doc, ref string) (out A, err error) {
+	val, err := doc.Resolve(ref)
+	if err != nil {
+		return out, err
+	}
+
+	out, ok := val.(A)
+	if !ok {
+		return out, fmt.Errorf(`[oas] reference %q resolved to %T, expected %T`, ref, val, out)
+	}
+	return out, nil
+}
@@ -0,0 +1,100 @@
+package oas
+
+import (
+	"fmt"
+	r "reflect"
+	"strconv"
+	"strings"
+)
+
+func ptrResolve(val r.Value, ptr string) (r.Value, error) {
+	if ptr != `` && ptr[0] != '/' {
+		return r.Value{}, fmt.Errorf(`[oas] invalid JSON Pointer %q: must be empty or start with "/"`, ptr)
+	}
+
+	val = ptrDeref(val)
+	if ptr == `` {
+		return val, nil
+	}
+
+	var cur string
+	for _, tok := range strings.Split(ptr[1:], `/`) {
+		tok = ptrUnescaper.Replace(tok)
+		cur = ptrAppend(cur, tok)
+
+		val = ptrDeref(ptrStep(val, tok))
+		if !val.IsValid() {
+			return r.Value{}, fmt.Errorf(`[oas] failed to resolve JSON Pointer: nothing at %q`, cur)
+		}
+	}
+	return val, nil
+}
+
+func ptrDeref(val r.Value) r.Value {
+	for val.Kind() == r.Ptr || val.Kind() == r.Interface {
+		if val.IsNil() {
+			return r.Value{}
+		}
+		val = val.Elem()
+	}
+	return val
+}
+
+func ptrStep(val r.Value, tok string) r.Value {
+	switch val.Kind() {
+	case r.Struct:
+		if !isOwnType(val.Type()) {
+			return r.Value{}
+		}
+
+		fields := jsonFieldsOf(val.Type())
+		for _, field := range fields {
+			if !field.inline && field.name == tok {
+				out := val.FieldByIndex(field.index)
+				if field.omitEmpty && isValueEmpty(out) {
+					return r.Value{}
+				}
+				return out
+			}
+		}
+
+		for _, field := range fields {
+			if field.inline {
+				out := ptrStep(val.FieldByIndex(field.index), tok)
+				if out.IsValid() {
+					return out
+				}
+			}
+		}
+		return r.Value{}
+
+	case r.Map:
+		if val.Type().Key().Kind() != r.String {
+			return r.Value{}
+		}
+		return val.MapIndex(r.ValueOf(tok).Convert(val.Type().Key()))
+
+	case r.Slice, r.Array:
+		ind, err := strconv.Atoi(tok)
+		if err != nil || ind < 0 || ind >= val.Len() || tok != strconv.Itoa(ind) {
+			return r.Value{}
+		}
+		return val.Index(ind)
+
+	default:
+		return r.Value{}
+	}
+}
+
+// Returns `.Ref` of the given OAS object, if any.
+func refOf(val any) string {
+	rval := r.ValueOf(val)
+	if rval.Kind() != r.Struct || !isOwnType(rval.Type()) {
+		return ``
+	}
+	field := rval.FieldByName(`Ref`)
+	if field.Kind() != r.String {
+		return ``
+	}
+	return field.String()
+}
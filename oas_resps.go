@@ -0,0 +1,154 @@
+package oas
+
+/*
+Fluent builder for `oas.Resps`, created via `oas.Doc.Responses`. Every
+response gets a description, as required by the spec: either the given one, or
+a default derived from the status, such as "Not Found" for "404" or "Client
+error" for "4XX". Statuses are keys of `oas.Resps`: codes such as "201", ranges
+such as "4XX", or "default". Example:
+
+	doc.Route(`/persons`, http.MethodPost, oas.Op{
+		ReqBody: doc.JsonBodyOpt(Person{}),
+		Resps: doc.Responses().
+			Created(Person{}, ``).
+			Shared(`4XX`, `ClientError`, doc.JsonResp(ErrBody{}, `Invalid request.`)).
+			Json(`default`, ErrBody{}, ``).
+			Done(),
+	})
+*/
+type RespsBuilder struct {
+	doc *Doc
+	out Resps
+}
+
+// Starts building responses. See `oas.RespsBuilder`.
+func (self *Doc) Responses() *RespsBuilder {
+	return &RespsBuilder{doc: self, out: Resps{}}
+}
+
+/*
+Shortcut. Returns `oas.Resp` with a JSON body of the given type and the given
+description, after registering its schema in the document. If the type is
+nil, the response has no content.
+*/
+func (self *Doc) JsonResp(typ any, desc string) Resp {
+	out := Resp{Desc: desc}
+	if typ != nil {
+		out.Cont = MediaTypes{ConTypeJson: self.SchemaMedia(typ)}
+	}
+	return out
+}
+
+/*
+Adds the given response for the given status. If the description is empty,
+uses the default for the status. Panics if the status already has a response.
+*/
+func (self *RespsBuilder) Status(status string, resp Resp) *RespsBuilder {
+	_, ok := self.out[status]
+	if ok {
+		panic(errRespsDuplicate(status))
+	}
+	if resp.Desc == `` && resp.Ref == `` {
+		resp.Desc = RespDesc(status)
+	}
+	self.out[status] = resp
+	return self
+}
+
+// Adds a JSON response of the given type. See `oas.Doc.JsonResp`.
+func (self *RespsBuilder) Json(status string, typ any, desc string) *RespsBuilder {
+	return self.Status(status, self.doc.JsonResp(typ, desc))
+}
+
+// Shortcut for a "200" JSON response of the given type.
+func (self *RespsBuilder) Ok(typ any, desc string) *RespsBuilder {
+	return self.Json(`200`, typ, desc)
+}
+
+/*
+Adds a "201" response with a required "Location" header, and a JSON body of
+the given type, if non-nil.
+*/
+func (self *RespsBuilder) Created(typ any, desc string) *RespsBuilder {
+	resp := self.doc.JsonResp(typ, desc)
+	resp.Head = Heads{`Location`: {
+		Desc:   `URL of the created resource.`,
+		Requ:   true,
+		Schema: &Schema{Type: []string{TypeStr}, Format: `uri-reference`},
+	}}
+	return self.Status(`201`, resp)
+}
+
+// Adds a "204" response without content.
+func (self *RespsBuilder) NoContent(desc string) *RespsBuilder {
+	return self.Status(`204`, Resp{Desc: desc})
+}
+
+/*
+Adds a reference to the response component with the given name, which
+doesn't need to exist yet.
+*/
+func (self *RespsBuilder) Ref(status, name string) *RespsBuilder {
+	return self.Status(status, Resp{Ref: RespRef(name)})
+}
+
+/*
+Registers the response in `.Comps.Resps` under the given name, and adds a
+reference to it. Useful for error responses shared between operations. If
+the description is empty, uses the default for the status. Registering an
+identical component again is a no-op; registering a different one under the
+same name panics.
+*/
+func (self *RespsBuilder) Shared(status, name string, resp Resp) *RespsBuilder {
+	if resp.Desc == `` {
+		resp.Desc = RespDesc(status)
+	}
+	self.doc.compResp(name, resp)
+	return self.Ref(status, name)
+}
+
+/*
+Adds a header to the response for the given status, which must have been
+added before, and must not be a reference.
+*/
+func (self *RespsBuilder) Header(status, name string, head Head) *RespsBuilder {
+	resp, ok := self.out[status]
+	if !ok || resp.Ref != `` {
+		panic(errRespsHeader(status, name))
+	}
+	if resp.Head == nil {
+		resp.Head = Heads{}
+	}
+	resp.Head[name] = head
+	self.out[status] = resp
+	return self
+}
+
+// Returns the built responses.
+func (self *RespsBuilder) Done() Resps { return self.out }
+
+// Returns a reference to the response component with the given name.
+func RespRef(name string) string { return ptrAppend(`#/components/responses`, name) }
+
+/*
+Default description for a response status: the standard status text for
+codes, such as "Not Found" for "404", a description of the class for ranges
+such as "4XX", and "Unexpected response" for "default".
+*/
+func RespDesc(status string) string {
+	if len(status) == 3 && (status[1:] == `XX` || status[1:] == `xx`) {
+		desc, ok := respRangeDescs[status[0]]
+		if ok {
+			return desc
+		}
+	}
+	if status == `default` {
+		return `Unexpected response`
+	}
+
+	desc := respStatusText(status)
+	if desc == `` {
+		return `Response`
+	}
+	return desc
+}
@@ -0,0 +1,46 @@
+package oas
+
+import (
+	"fmt"
+	"net/http"
+	r "reflect"
+	"strconv"
+)
+
+var respRangeDescs = map[byte]string{
+	'1': `Informational response`,
+	'2': `Success`,
+	'3': `Redirection`,
+	'4': `Client error`,
+	'5': `Server error`,
+}
+
+func respStatusText(status string) string {
+	code, err := strconv.Atoi(status)
+	if err != nil {
+		return ``
+	}
+	return http.StatusText(code)
+}
+
+func (self *Doc) compResp(name string, resp Resp) {
+	prev, ok := self.Comps.Resps[name]
+	if ok {
+		if !r.DeepEqual(prev, resp) {
+			panic(fmt.Errorf(`[oas] redundant non-identical response component %q`, name))
+		}
+		return
+	}
+	if self.Comps.Resps == nil {
+		self.Comps.Resps = Resps{}
+	}
+	self.Comps.Resps[name] = resp
+}
+
+func errRespsDuplicate(status string) error {
+	return fmt.Errorf(`[oas] redundant response for status %q`, status)
+}
+
+func errRespsHeader(status, name string) error {
+	return fmt.Errorf(`[oas] unable to add header %q: no non-reference response for status %q`, name, status)
+}
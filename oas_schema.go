@@ -37,6 +37,12 @@ func NullSchema(name string, inner Schema) Schema {
 	}
 }
 
+/*
+Shortcut for making a boolean schema. `true` accepts any instance, `false`
+rejects every instance.
+*/
+func BoolSchema(val bool) Schema { return Schema{Bool: &val} }
+
 /*
 References:
 
@@ -53,9 +59,23 @@ type Schema struct {
 	// Ref `json:",omitempty" yaml:",omitempty" toml:",omitempty"`
 	// Ref
 	Ref  string `json:"$ref,omitempty"        yaml:"$ref,omitempty"        toml:"$ref,omitempty"`
-	Sum  string `json:"sum,omitempty"         yaml:"sum,omitempty"         toml:"sum,omitempty"`
+	Sum  string `json:"summary,omitempty"     yaml:"summary,omitempty"     toml:"summary,omitempty"`
 	Desc string `json:"description,omitempty" yaml:"description,omitempty" toml:"description,omitempty"`
 
+	/**
+	JSON Schema core identifiers, references and comments. Reference:
+
+		https://datatracker.ietf.org/doc/html/draft-bhutton-json-schema-00#section-8
+	*/
+
+	Id        string  `json:"$id,omitempty"            yaml:"$id,omitempty"            toml:"$id,omitempty"`
+	Dialect   string  `json:"$schema,omitempty"        yaml:"$schema,omitempty"        toml:"$schema,omitempty"`
+	Anchor    string  `json:"$anchor,omitempty"        yaml:"$anchor,omitempty"        toml:"$anchor,omitempty"`
+	DynRef    string  `json:"$dynamicRef,omitempty"    yaml:"$dynamicRef,omitempty"    toml:"$dynamicRef,omitempty"`
+	DynAnchor string  `json:"$dynamicAnchor,omitempty" yaml:"$dynamicAnchor,omitempty" toml:"$dynamicAnchor,omitempty"`
+	Comment   string  `json:"$comment,omitempty"       yaml:"$comment,omitempty"       toml:"$comment,omitempty"`
+	Defs      Schemas `json:"$defs,omitempty"          yaml:"$defs,omitempty"          toml:"$defs,omitempty"`
+
 	/**
 	OAS properties.
 	*/
@@ -112,16 +132,16 @@ type Schema struct {
 	// Validation for any instance.
 	// https://datatracker.ietf.org/doc/html/draft-bhutton-json-schema-validation-00#section-6.1
 	Type  []string `json:"type,omitempty"  yaml:"type,omitempty"  toml:"type,omitempty"`
-	Enum  []string `json:"enum,omitempty"  yaml:"enum,omitempty"  toml:"enum,omitempty"`
+	Enum  []any    `json:"enum,omitempty"  yaml:"enum,omitempty"  toml:"enum,omitempty"`
 	Const any      `json:"const,omitempty" yaml:"const,omitempty"                       toml:"const,omitempty"`
 
 	// Validation for numeric instances.
 	// https://datatracker.ietf.org/doc/html/draft-bhutton-json-schema-validation-00#section-6.2
-	MulOf   uint64 `json:"multipleOf,omitempty"       yaml:"multipleOf,omitempty"       toml:"multipleOf,omitempty"` // 0 represents "missing".
-	Max     *int64 `json:"maximum,omitempty"          yaml:"maximum,omitempty"          toml:"maximum,omitempty"`
-	ExlcMax *int64 `json:"exclusiveMaximum,omitempty" yaml:"exclusiveMaximum,omitempty" toml:"exclusiveMaximum,omitempty"`
-	Min     *int64 `json:"minimum,omitempty"          yaml:"minimum,omitempty"          toml:"minimum,omitempty"`
-	ExclMin *int64 `json:"exclusiveMinimum,omitempty" yaml:"exclusiveMinimum,omitempty" toml:"exclusiveMinimum,omitempty"`
+	MulOf   float64  `json:"multipleOf,omitempty"       yaml:"multipleOf,omitempty"       toml:"multipleOf,omitempty"` // 0 represents "missing".
+	Max     *float64 `json:"maximum,omitempty"          yaml:"maximum,omitempty"          toml:"maximum,omitempty"`
+	ExlcMax *float64 `json:"exclusiveMaximum,omitempty" yaml:"exclusiveMaximum,omitempty" toml:"exclusiveMaximum,omitempty"`
+	Min     *float64 `json:"minimum,omitempty"          yaml:"minimum,omitempty"          toml:"minimum,omitempty"`
+	ExclMin *float64 `json:"exclusiveMinimum,omitempty" yaml:"exclusiveMinimum,omitempty" toml:"exclusiveMinimum,omitempty"`
 
 	// Validation for strings.
 	// https://datatracker.ietf.org/doc/html/draft-bhutton-json-schema-validation-00#section-6.3
@@ -141,7 +161,7 @@ type Schema struct {
 	// https://datatracker.ietf.org/doc/html/draft-bhutton-json-schema-validation-00#section-6.5
 	MaxProps uint64              `json:"maxProperties,omitempty"     yaml:"maxProperties,omitempty"     toml:"maxProperties,omitempty"`
 	MinProps uint64              `json:"minProperties,omitempty"     yaml:"minProperties,omitempty"     toml:"minProperties,omitempty"`
-	Requ     bool                `json:"required,omitempty"          yaml:"required,omitempty"          toml:"required,omitempty"`
+	Requ     []string            `json:"required,omitempty"          yaml:"required,omitempty"          toml:"required,omitempty"`
 	DepRequ  map[string][]string `json:"dependentRequired,omitempty" yaml:"dependentRequired,omitempty" toml:"dependentRequired,omitempty"`
 
 	// Format.
@@ -150,19 +170,33 @@ type Schema struct {
 
 	// Validation of string-encoded data.
 	// https://datatracker.ietf.org/doc/html/draft-bhutton-json-schema-validation-00#section-8
-	ContEnc    string `json:"contentEncoding,omitempty"  yaml:"contentEncoding,omitempty"  toml:"contentEncoding,omitempty"`
-	ContMedia  string `json:"contentMediaType,omitempty" yaml:"contentMediaType,omitempty" toml:"contentMediaType,omitempty"`
-	ContSchema string `json:"contentSchema,omitempty"    yaml:"contentSchema,omitempty"    toml:"contentSchema,omitempty"`
+	ContEnc    string  `json:"contentEncoding,omitempty"  yaml:"contentEncoding,omitempty"  toml:"contentEncoding,omitempty"`
+	ContMedia  string  `json:"contentMediaType,omitempty" yaml:"contentMediaType,omitempty" toml:"contentMediaType,omitempty"`
+	ContSchema *Schema `json:"contentSchema,omitempty"    yaml:"contentSchema,omitempty"    toml:"contentSchema,omitempty"`
 
 	// Metadata annotations.
 	// https://datatracker.ietf.org/doc/html/draft-bhutton-json-schema-validation-00#section-9
 	Title string `json:"title,omitempty"       yaml:"title,omitempty"       toml:"title,omitempty"`
 	// Desc     string   `json:"description,omitempty" yaml:"description,omitempty" toml:"description,omitempty"`
-	Default  any      `json:"default,omitempty"     yaml:"default,omitempty"     toml:"default,omitempty"`
-	Depr     bool     `json:"deprecated,omitempty"  yaml:"deprecated,omitempty"  toml:"deprecated,omitempty"`
-	Ronly    bool     `json:"readOnly,omitempty"    yaml:"readOnly,omitempty"    toml:"readOnly,omitempty"`
-	Wonly    bool     `json:"writeOnly,omitempty"   yaml:"writeOnly,omitempty"   toml:"writeOnly,omitempty"`
-	Examples Examples `json:"examples,omitempty"    yaml:"examples,omitempty"    toml:"examples,omitempty"`
+	Default  any   `json:"default,omitempty"     yaml:"default,omitempty"     toml:"default,omitempty"`
+	Depr     bool  `json:"deprecated,omitempty"  yaml:"deprecated,omitempty"  toml:"deprecated,omitempty"`
+	Ronly    bool  `json:"readOnly,omitempty"    yaml:"readOnly,omitempty"    toml:"readOnly,omitempty"`
+	Wonly    bool  `json:"writeOnly,omitempty"   yaml:"writeOnly,omitempty"   toml:"writeOnly,omitempty"`
+	Examples []any `json:"examples,omitempty"    yaml:"examples,omitempty"    toml:"examples,omitempty"`
+
+	/**
+	Non-keyword fields.
+	*/
+
+	/**
+	Represents a boolean schema: `true` accepts any instance, `false` rejects
+	every instance. When non-nil, all other fields are ignored, and the schema
+	is encoded as a JSON boolean. See `oas.BoolSchema`.
+	*/
+	Bool *bool `json:"-" yaml:"-" toml:"-"`
+
+	// Specification extensions and unrecognized keywords, encoded inline.
+	Exts Anys `json:"-" yaml:",inline" toml:"-"`
 }
 
 // Returns `.Title` after validating that it's non-empty.
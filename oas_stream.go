@@ -0,0 +1,94 @@
+package oas
+
+/*
+Extension used instead of `oas.MediaType.ItemSchema` for documents targeting
+OAS versions before 3.2, which don't support "itemSchema". Its value is
+`oas.Schema`, including when decoded via `oas.DecodeJson`.
+*/
+const ExtItemSchema = `x-itemSchema`
+
+/*
+Returns a media type describing a stream of items of the given type, such as
+NDJSON, after registering its schema in the document. The input is used only
+as a type carrier. When `.Openapi` targets OAS 3.2, uses
+`oas.MediaType.ItemSchema`, otherwise the `oas.ExtItemSchema` extension.
+*/
+func (self *Doc) StreamMedia(typ any) MediaType {
+	return self.itemMedia(self.Sch(typ))
+}
+
+/*
+Returns a response streaming items of the given type in any of the given
+media types, defaulting to NDJSON. See `oas.Doc.StreamMedia`. If the
+description is empty, uses a generic one.
+*/
+func (self *Doc) StreamResp(typ any, desc string, conTypes ...string) Resp {
+	if len(conTypes) == 0 {
+		conTypes = []string{ConTypeNdjson}
+	}
+	if desc == `` {
+		desc = `Stream of items.`
+	}
+
+	media := self.StreamMedia(typ)
+	out := Resp{Desc: desc, Cont: make(MediaTypes, len(conTypes))}
+	for _, val := range conTypes {
+		out.Cont[val] = media
+	}
+	return out
+}
+
+/*
+Describes one kind of server-sent event for `oas.Doc.SseMedia`. `.Event` is
+the event name; empty means the default "message" event, sent without the
+"event" field. `.Data` is a type carrier for the event data: strings are sent
+as-is, other types as JSON. A nil `.Data` means arbitrary text.
+*/
+type SseEvent struct {
+	Event string
+	Data  any
+	Desc  string
+}
+
+/*
+Returns a "text/event-stream" media type whose items are server-sent events
+with the fields "event", "data", "id" and "retry", as described in OAS 3.2.
+If multiple events are given, the item schema is their union via "oneOf",
+discriminated by "event". The event data is described by "contentMediaType"
+and "contentSchema". Like `oas.Doc.StreamMedia`, uses "itemSchema" or its
+extension depending on the target version. Reference:
+
+	https://spec.openapis.org/oas/v3.2.0#server-sent-event-streams
+*/
+func (self *Doc) SseMedia(events ...SseEvent) MediaType {
+	if len(events) == 0 {
+		events = []SseEvent{{}}
+	}
+	if len(events) == 1 {
+		return self.itemMedia(self.sseSchema(events[0]))
+	}
+
+	var sch Schema
+	for _, val := range events {
+		sch.OneOf = append(sch.OneOf, self.sseSchema(val))
+	}
+	return self.itemMedia(sch)
+}
+
+// Returns a response with a stream of server-sent events. See `oas.Doc.SseMedia`.
+func (self *Doc) SseResp(desc string, events ...SseEvent) Resp {
+	if desc == `` {
+		desc = `Stream of server-sent events.`
+	}
+	return Resp{Desc: desc, Cont: MediaTypes{ConTypeSse: self.SseMedia(events...)}}
+}
+
+// Adds a streaming response for the given status. See `oas.Doc.StreamResp`.
+func (self *RespsBuilder) Stream(status string, typ any, desc string, conTypes ...string) *RespsBuilder {
+	return self.Status(status, self.doc.StreamResp(typ, desc, conTypes...))
+}
+
+// Adds a server-sent events response for the given status. See `oas.Doc.SseResp`.
+func (self *RespsBuilder) Sse(status, desc string, events ...SseEvent) *RespsBuilder {
+	return self.Status(status, self.doc.SseResp(desc, events...))
+}
@@ -0,0 +1,43 @@
+package oas
+
+import (
+	r "reflect"
+)
+
+func (self *Doc) itemMedia(sch Schema) MediaType {
+	if self.isVer32() {
+		return MediaType{ItemSchema: &sch}
+	}
+	return MediaType{Exts: Anys{ExtItemSchema: sch}}
+}
+
+func (self *Doc) sseSchema(src SseEvent) Schema {
+	str := Schema{Type: []string{TypeStr}}
+	min := 0.0
+
+	data := str
+	typ := r.TypeOf(src.Data)
+	if typ != nil && typ.Kind() != r.String {
+		data.ContMedia = ConTypeJson
+		data.ContSchema = self.Sch(src.Data).Opt()
+	}
+
+	out := Schema{
+		Type: []string{TypeObj},
+		Desc: src.Desc,
+		Props: Schemas{
+			`data`:  data,
+			`id`:    str,
+			`retry`: {Type: []string{TypeInt}, Min: &min},
+		},
+		Requ: []string{`data`},
+	}
+
+	if src.Event == `` {
+		out.Props[`event`] = Schema{Type: []string{TypeStr}, Enum: []any{`message`}}
+	} else {
+		out.Props[`event`] = Schema{Type: []string{TypeStr}, Const: src.Event}
+		out.Requ = append(out.Requ, `event`)
+	}
+	return out
+}
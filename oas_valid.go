@@ -0,0 +1,93 @@
+package oas
+
+import (
+	"fmt"
+	"strings"
+)
+
+/*
+Validates an instance against the given schema, which may be a reference
+resolved within the document. The instance may be a value decoded from JSON
+into `any`, with or without `json.Number`, or any other value, which is first
+converted by encoding it as JSON. Returns nil if valid, otherwise a non-empty
+`oas.ValidErrs`.
+
+Supports the JSON Schema 2020-12 keywords modeled by `oas.Schema`, including
+`$ref` with sibling keywords, boolean schemas, `unevaluatedProperties` and
+`unevaluatedItems`. `pattern` and `patternProperties` use Go regexp syntax
+(RE2), which differs from ECMA 262 in some advanced features. The following
+formats are validated; others are ignored: "date-time", "date", "time",
+"duration", "uuid", "email", "ipv4", "ipv6", "uri", "int32", "int64".
+
+Limitations of the `oas.Schema` representation also apply: for example, the
+zero values of numeric keywords such as `maxLength` mean "unset". A nil
+`const` is unset; to require null, use `oas.Null`.
+
+Numbers with exponents beyond 1000 in either direction, such as "1e1000000",
+fail numeric keywords and integer formats, and are not integers, since
+comparing them exactly is too expensive.
+*/
+func (self *Doc) Validate(sch Schema, val any) error {
+	val, err := validNorm(val)
+	if err != nil {
+		return err
+	}
+
+	var state validState
+	validator{doc: self}.schema(&state, validLoc{}, sch, val, 0)
+	if len(state.errs) > 0 {
+		return state.errs
+	}
+	return nil
+}
+
+/*
+Shortcut for validating a JSON-encoded instance. Returns an error if the input
+is not valid JSON. See `oas.Doc.Validate`.
+*/
+func (self *Doc) ValidateJson(sch Schema, src []byte) error {
+	val, err := jsonParse(src)
+	if err != nil {
+		return fmt.Errorf(`[oas] failed to decode JSON for validation: %w`, err)
+	}
+	return self.Validate(sch, val)
+}
+
+/*
+Single validation failure. Modeled after the "basic" output format of JSON
+Schema, and encodes to JSON as such.
+*/
+type ValidErr struct {
+	// JSON Pointer to the invalid part of the instance.
+	Inst string `json:"instanceLocation"`
+
+	// JSON Pointer to the failing keyword, relative to the root schema,
+	// including any traversed "$ref".
+	Kw string `json:"keywordLocation"`
+
+	// Location of the failing keyword after following references, such as
+	// "#/components/schemas/User/properties/id/type". Empty if no reference
+	// was followed.
+	Abs string `json:"absoluteKeywordLocation,omitempty"`
+
+	Msg string `json:"error"`
+}
+
+// Implement `error`.
+func (self ValidErr) Error() string {
+	return fmt.Sprintf(`at %q (schema %q): %v`, self.Inst, self.Kw, self.Msg)
+}
+
+// Error type returned by `oas.Doc.Validate`.
+type ValidErrs []ValidErr
+
+// Implement `error`.
+func (self ValidErrs) Error() string {
+	var buf strings.Builder
+	buf.WriteString(`[oas] validation failed:`)
+	for _, val := range self {
+		buf.WriteString("\n\t")
+		buf.WriteString(val.Error())
+	}
+	return buf.String()
+}
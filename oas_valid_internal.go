@@ -0,0 +1,697 @@
+package oas
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/big"
+	"net"
+	"net/mail"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+/*
+Maximum nesting of schemas during validation. Exceeding it indicates a cycle
+which doesn't consume the instance, such as a schema referencing itself via
+`allOf`.
+*/
+const validMaxDepth = 256
+
+type validLoc struct{ inst, kw, abs string }
+
+func (self validLoc) key(key string) validLoc {
+	self.kw = ptrAppend(self.kw, key)
+	if self.abs != `` {
+		self.abs = ptrAppend(self.abs, key)
+	}
+	return self
+}
+
+func (self validLoc) at(key string) validLoc {
+	self.inst = ptrAppend(self.inst, key)
+	return self
+}
+
+/*
+Errors and annotations collected while validating one instance. Annotations
+track which properties and items were evaluated, for `unevaluatedProperties`
+and `unevaluatedItems`.
+*/
+type validState struct {
+	errs  ValidErrs
+	props map[string]bool
+	items map[int]bool
+}
+
+func (self *validState) fail(loc validLoc, msg string) {
+	self.errs = append(self.errs, ValidErr{Inst: loc.inst, Kw: loc.kw, Abs: loc.abs, Msg: msg})
+}
+
+func (self *validState) failf(loc validLoc, pat string, args ...any) {
+	self.fail(loc, fmt.Sprintf(pat, args...))
+}
+
+func (self *validState) evalProp(key string) {
+	if self.props == nil {
+		self.props = map[string]bool{}
+	}
+	self.props[key] = true
+}
+
+func (self *validState) evalItem(ind int) {
+	if self.items == nil {
+		self.items = map[int]bool{}
+	}
+	self.items[ind] = true
+}
+
+// Merges annotations, but not errors.
+func (self *validState) annotate(src validState) {
+	for key := range src.props {
+		self.evalProp(key)
+	}
+	for ind := range src.items {
+		self.evalItem(ind)
+	}
+}
+
+// Merges errors and annotations.
+func (self *validState) merge(src validState) {
+	self.errs = append(self.errs, src.errs...)
+	if len(src.errs) == 0 {
+		self.annotate(src)
+	}
+}
+
+type validator struct{ doc *Doc }
+
+// Validates an instance against a subschema, separately from the caller.
+func (self validator) sub(loc validLoc, sch Schema, inst any, depth int) (out validState) {
+	self.schema(&out, loc, sch, inst, depth+1)
+	return
+}
+
+func (self validator) schema(state *validState, loc validLoc, sch Schema, inst any, depth int) {
+	if depth > validMaxDepth {
+		state.fail(loc, `maximum schema depth exceeded, possibly due to a reference cycle`)
+		return
+	}
+
+	if sch.Bool != nil {
+		if !*sch.Bool {
+			state.fail(loc, `boolean schema "false" rejects every instance`)
+		}
+		return
+	}
+
+	if sch.Ref != `` {
+		self.ref(state, loc.key(`$ref`), sch.Ref, inst, depth)
+	}
+
+	self.generic(state, loc, sch, inst)
+
+	switch inst := inst.(type) {
+	case json.Number:
+		self.number(state, loc, sch, inst)
+	case string:
+		self.string(state, loc, sch, inst)
+	case []any:
+		self.array(state, loc, sch, inst, depth)
+	case map[string]any:
+		self.object(state, loc, sch, inst, depth)
+	}
+
+	self.applicators(state, loc, sch, inst, depth)
+	self.unevaluated(state, loc, sch, inst, depth)
+}
+
+func (self validator) ref(state *validState, loc validLoc, ref string, inst any, depth int) {
+	tar, err := ResolveAs[Schema](self.doc, ref)
+	if err != nil {
+		state.fail(loc, err.Error())
+		return
+	}
+	loc.abs = ref
+	state.merge(self.sub(loc, tar, inst, depth))
+}
+
+func (validator) generic(state *validState, loc validLoc, sch Schema, inst any) {
+	if len(sch.Type) > 0 && !validTypeHas(sch.Type, inst) {
+		state.failf(loc.key(`type`), `expected type %v, got %v`, strings.Join(sch.Type, ` or `), validTypeOf(inst))
+	}
+
+	if len(sch.Enum) > 0 && !validOneOf(sch.Enum, inst) {
+		state.fail(loc.key(`enum`), `value is not one of the allowed values`)
+	}
+
+	if sch.Const != nil && !validEqualNorm(sch.Const, inst) {
+		state.fail(loc.key(`const`), `value doesn't match the constant`)
+	}
+}
+
+func (validator) number(state *validState, loc validLoc, sch Schema, inst json.Number) {
+	if sch.MulOf <= 0 && sch.Max == nil && sch.ExlcMax == nil && sch.Min == nil && sch.ExclMin == nil &&
+		sch.Format != FormatInt32 && sch.Format != FormatInt64 {
+		return
+	}
+
+	num, ok := validRatParse(inst.String())
+	if !ok {
+		state.failf(loc, `invalid or out-of-range number %q`, inst)
+		return
+	}
+
+	if sch.MulOf > 0 && !new(big.Rat).Quo(num, validRat(sch.MulOf)).IsInt() {
+		state.failf(loc.key(`multipleOf`), `%v is not a multiple of %v`, inst, sch.MulOf)
+	}
+	if sch.Max != nil && num.Cmp(validRat(*sch.Max)) > 0 {
+		state.failf(loc.key(`maximum`), `%v is greater than %v`, inst, *sch.Max)
+	}
+	if sch.ExlcMax != nil && num.Cmp(validRat(*sch.ExlcMax)) >= 0 {
+		state.failf(loc.key(`exclusiveMaximum`), `%v is not less than %v`, inst, *sch.ExlcMax)
+	}
+	if sch.Min != nil && num.Cmp(validRat(*sch.Min)) < 0 {
+		state.failf(loc.key(`minimum`), `%v is less than %v`, inst, *sch.Min)
+	}
+	if sch.ExclMin != nil && num.Cmp(validRat(*sch.ExclMin)) <= 0 {
+		state.failf(loc.key(`exclusiveMinimum`), `%v is not greater than %v`, inst, *sch.ExclMin)
+	}
+
+	if !validIntFormat(sch.Format, num) {
+		state.failf(loc.key(`format`), `%v is out of range for format %q`, inst, sch.Format)
+	}
+}
+
+func (validator) string(state *validState, loc validLoc, sch Schema, inst string) {
+	size := uint64(utf8.RuneCountInString(inst))
+
+	if sch.MaxLen > 0 && size > sch.MaxLen {
+		state.failf(loc.key(`maxLength`), `length %v is greater than %v`, size, sch.MaxLen)
+	}
+	if sch.MinLen > 0 && size < sch.MinLen {
+		state.failf(loc.key(`minLength`), `length %v is less than %v`, size, sch.MinLen)
+	}
+
+	if sch.Pattern != `` {
+		reg, err := validRegexp(sch.Pattern)
+		if err != nil {
+			state.fail(loc.key(`pattern`), err.Error())
+		} else if !reg.MatchString(inst) {
+			state.failf(loc.key(`pattern`), `string doesn't match pattern %q`, sch.Pattern)
+		}
+	}
+
+	if !validStrFormat(sch.Format, inst) {
+		state.failf(loc.key(`format`), `string is not a valid %q`, sch.Format)
+	}
+}
+
+func (self validator) array(state *validState, loc validLoc, sch Schema, inst []any, depth int) {
+	size := uint64(len(inst))
+
+	if sch.MaxItems > 0 && size > sch.MaxItems {
+		state.failf(loc.key(`maxItems`), `array length %v is greater than %v`, size, sch.MaxItems)
+	}
+	if sch.MinItems > 0 && size < sch.MinItems {
+		state.failf(loc.key(`minItems`), `array length %v is less than %v`, size, sch.MinItems)
+	}
+
+	if sch.UniqItems {
+	outer:
+		for ind := range inst {
+			for prev := range inst[:ind] {
+				if validEqual(inst[prev], inst[ind]) {
+					state.failf(loc.key(`uniqueItems`), `items at %v and %v are equal`, prev, ind)
+					break outer
+				}
+			}
+		}
+	}
+
+	for ind, val := range inst {
+		ptr := strconv.Itoa(ind)
+		if ind < len(sch.PrefixItems) {
+			state.errs = append(state.errs, self.sub(loc.key(`prefixItems`).key(ptr).at(ptr), sch.PrefixItems[ind], val, depth).errs...)
+			state.evalItem(ind)
+		} else if sch.Items != nil {
+			state.errs = append(state.errs, self.sub(loc.key(`items`).at(ptr), *sch.Items, val, depth).errs...)
+			state.evalItem(ind)
+		}
+	}
+
+	if sch.Contains != nil {
+		var count uint64
+		for ind, val := range inst {
+			ptr := strconv.Itoa(ind)
+			if len(self.sub(loc.key(`contains`).at(ptr), *sch.Contains, val, depth).errs) == 0 {
+				count++
+				state.evalItem(ind)
+			}
+		}
+
+		least := sch.MinCont
+		if least == 0 {
+			least = 1
+		}
+		if count < least {
+			state.failf(loc.key(`contains`), `array contains %v matching items, expected at least %v`, count, least)
+		}
+		if sch.MaxCont > 0 && count > sch.MaxCont {
+			state.failf(loc.key(`maxContains`), `array contains %v matching items, expected at most %v`, count, sch.MaxCont)
+		}
+	}
+}
+
+func (self validator) object(state *validState, loc validLoc, sch Schema, inst map[string]any, depth int) {
+	size := uint64(len(inst))
+	keys := sortedKeys(inst)
+
+	if sch.MaxProps > 0 && size > sch.MaxProps {
+		state.failf(loc.key(`maxProperties`), `object has %v properties, expected at most %v`, size, sch.MaxProps)
+	}
+	if sch.MinProps > 0 && size < sch.MinProps {
+		state.failf(loc.key(`minProperties`), `object has %v properties, expected at least %v`, size, sch.MinProps)
+	}
+
+	for _, key := range sch.Requ {
+		_, ok := inst[key]
+		if !ok {
+			state.failf(loc.key(`required`), `missing required property %q`, key)
+		}
+	}
+
+	for _, key := range sortedKeys(sch.DepRequ) {
+		_, ok := inst[key]
+		if !ok {
+			continue
+		}
+		for _, dep := range sch.DepRequ[key] {
+			_, ok := inst[dep]
+			if !ok {
+				state.failf(loc.key(`dependentRequired`).key(key), `property %q requires property %q`, key, dep)
+			}
+		}
+	}
+
+	for _, key := range keys {
+		val := inst[key]
+		var matched bool
+
+		if sch.PropNames != nil {
+			state.errs = append(state.errs, self.sub(loc.key(`propertyNames`).at(key), *sch.PropNames, key, depth).errs...)
+		}
+
+		sub, ok := sch.Props[key]
+		if ok {
+			matched = true
+			state.errs = append(state.errs, self.sub(loc.key(`properties`).key(key).at(key), sub, val, depth).errs...)
+			state.evalProp(key)
+		}
+
+		for _, pat := range sortedKeys(sch.PatProps) {
+			reg, err := validRegexp(pat)
+			if err != nil {
+				state.fail(loc.key(`patternProperties`).key(pat), err.Error())
+				continue
+			}
+			if !reg.MatchString(key) {
+				continue
+			}
+			matched = true
+			state.errs = append(state.errs, self.sub(loc.key(`patternProperties`).key(pat).at(key), sch.PatProps[pat], val, depth).errs...)
+			state.evalProp(key)
+		}
+
+		if !matched && sch.AddProps != nil {
+			state.errs = append(state.errs, self.sub(loc.key(`additionalProperties`).at(key), *sch.AddProps, val, depth).errs...)
+			state.evalProp(key)
+		}
+	}
+
+	for _, key := range sortedKeys(sch.DepSchemas) {
+		_, ok := inst[key]
+		if ok {
+			state.merge(self.sub(loc.key(`dependentSchemas`).key(key), sch.DepSchemas[key], inst, depth))
+		}
+	}
+}
+
+// In-place applicators: subschemas applied to the same instance.
+func (self validator) applicators(state *validState, loc validLoc, sch Schema, inst any, depth int) {
+	for ind, sub := range sch.AllOf {
+		state.merge(self.sub(loc.key(`allOf`).key(strconv.Itoa(ind)), sub, inst, depth))
+	}
+
+	if len(sch.AnyOf) > 0 {
+		loc := loc.key(`anyOf`)
+		var errs ValidErrs
+		var valid bool
+
+		for ind, sub := range sch.AnyOf {
+			out := self.sub(loc.key(strconv.Itoa(ind)), sub, inst, depth)
+			if len(out.errs) == 0 {
+				valid = true
+				state.annotate(out)
+			}
+			errs = append(errs, out.errs...)
+		}
+
+		if !valid {
+			state.fail(loc, `value doesn't match any subschema`)
+			state.errs = append(state.errs, errs...)
+		}
+	}
+
+	if len(sch.OneOf) > 0 {
+		loc := loc.key(`oneOf`)
+		var errs ValidErrs
+		var valid []int
+		var match validState
+
+		for ind, sub := range sch.OneOf {
+			out := self.sub(loc.key(strconv.Itoa(ind)), sub, inst, depth)
+			if len(out.errs) == 0 {
+				valid = append(valid, ind)
+				match = out
+			}
+			errs = append(errs, out.errs...)
+		}
+
+		if len(valid) == 0 {
+			state.fail(loc, `value doesn't match any subschema`)
+			state.errs = append(state.errs, errs...)
+		} else if len(valid) > 1 {
+			state.failf(loc, `value matches subschemas %v, expected exactly one`, valid)
+		} else {
+			state.annotate(match)
+		}
+	}
+
+	if sch.Not != nil && len(self.sub(loc.key(`not`), *sch.Not, inst, depth).errs) == 0 {
+		state.fail(loc.key(`not`), `value must not match the subschema`)
+	}
+
+	if sch.If != nil {
+		out := self.sub(loc.key(`if`), *sch.If, inst, depth)
+		if len(out.errs) == 0 {
+			state.annotate(out)
+			if sch.Then != nil {
+				state.merge(self.sub(loc.key(`then`), *sch.Then, inst, depth))
+			}
+		} else if sch.Else != nil {
+			state.merge(self.sub(loc.key(`else`), *sch.Else, inst, depth))
+		}
+	}
+}
+
+func (self validator) unevaluated(state *validState, loc validLoc, sch Schema, inst any, depth int) {
+	switch inst := inst.(type) {
+	case []any:
+		if sch.UnevalItems == nil {
+			return
+		}
+		for ind, val := range inst {
+			if state.items[ind] {
+				continue
+			}
+			ptr := strconv.Itoa(ind)
+			state.errs = append(state.errs, self.sub(loc.key(`unevaluatedItems`).at(ptr), *sch.UnevalItems, val, depth).errs...)
+			state.evalItem(ind)
+		}
+
+	case map[string]any:
+		if sch.UnevalProps == nil {
+			return
+		}
+		for _, key := range sortedKeys(inst) {
+			if state.props[key] {
+				continue
+			}
+			state.errs = append(state.errs, self.sub(loc.key(`unevaluatedProperties`).at(key), *sch.UnevalProps, inst[key], depth).errs...)
+			state.evalProp(key)
+		}
+	}
+}
+
+func validTypeOf(inst any) string {
+	switch inst.(type) {
+	case nil:
+		return TypeNull
+	case bool:
+		return TypeBool
+	case string:
+		return TypeStr
+	case json.Number:
+		return TypeNum
+	case []any:
+		return TypeArr
+	case map[string]any:
+		return TypeObj
+	default:
+		return fmt.Sprintf(`%T`, inst)
+	}
+}
+
+func validTypeHas(types []string, inst any) bool {
+	typ := validTypeOf(inst)
+	for _, val := range types {
+		if val == typ {
+			return true
+		}
+		if val == TypeInt && typ == TypeNum {
+			num, ok := validRatParse(inst.(json.Number).String())
+			if ok && num.IsInt() {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+/*
+Limit of number exponents. `big.Rat` expands the exponent into digits, so
+parsing "1e1000000" takes time and memory proportional to the exponent, which
+may come from an untrusted request body. Such numbers are far outside of the
+range of float64 schema keywords.
+*/
+const validExpMax = 1000
+
+/*
+Parses a JSON number exactly. False if the number is invalid or its exponent
+exceeds `validExpMax`.
+*/
+func validRatParse(src string) (*big.Rat, bool) {
+	ind := strings.IndexAny(src, `eE`)
+	if ind >= 0 {
+		exp, err := strconv.Atoi(strings.TrimPrefix(src[ind+1:], `+`))
+		if err != nil || exp > validExpMax || exp < -validExpMax {
+			return nil, false
+		}
+	}
+	return new(big.Rat).SetString(src)
+}
+
+// Exact decimal representation of a float, avoiding binary rounding errors.
+func validRat(val float64) *big.Rat {
+	out, _ := new(big.Rat).SetString(strconv.FormatFloat(val, 'g', -1, 64))
+	return out
+}
+
+func validOneOf(vals []any, inst any) bool {
+	for _, val := range vals {
+		if validEqualNorm(val, inst) {
+			return true
+		}
+	}
+	return false
+}
+
+// Like `validEqual` but normalizes the first value, which comes from a schema.
+func validEqualNorm(val, inst any) bool {
+	val, err := validNorm(val)
+	return err == nil && validEqual(val, inst)
+}
+
+// JSON equality of normalized values. Numbers are compared mathematically.
+func validEqual(one, two any) bool {
+	switch one := one.(type) {
+	case json.Number:
+		two, ok := two.(json.Number)
+		if !ok {
+			return false
+		}
+		if one == two {
+			return true
+		}
+		oneNum, ok0 := validRatParse(one.String())
+		twoNum, ok1 := validRatParse(two.String())
+		return ok0 && ok1 && oneNum.Cmp(twoNum) == 0
+
+	case []any:
+		two, ok := two.([]any)
+		if !ok || len(one) != len(two) {
+			return false
+		}
+		for ind := range one {
+			if !validEqual(one[ind], two[ind]) {
+				return false
+			}
+		}
+		return true
+
+	case map[string]any:
+		two, ok := two.(map[string]any)
+		if !ok || len(one) != len(two) {
+			return false
+		}
+		for key, val := range one {
+			other, ok := two[key]
+			if !ok || !validEqual(val, other) {
+				return false
+			}
+		}
+		return true
+
+	default:
+		return one == two
+	}
+}
+
+/*
+Converts the input to the representation produced by decoding JSON into `any`
+with `json.Number`. Values of other types are converted by encoding them as
+JSON and decoding back.
+*/
+func validNorm(val any) (any, error) {
+	out, ok := validNormFast(val)
+	if ok {
+		return out, nil
+	}
+
+	chunk, err := json.Marshal(val)
+	if err != nil {
+		return nil, fmt.Errorf(`[oas] failed to convert %T to JSON for validation: %w`, val, err)
+	}
+	return jsonParse(chunk)
+}
+
+func validNormFast(val any) (any, bool) {
+	switch val := val.(type) {
+	case nil, bool, string, json.Number:
+		return val, true
+	case float64:
+		return json.Number(strconv.FormatFloat(val, 'g', -1, 64)), !math.IsInf(val, 0) && !math.IsNaN(val)
+	case int:
+		return json.Number(strconv.Itoa(val)), true
+	case int64:
+		return json.Number(strconv.FormatInt(val, 10)), true
+
+	case []any:
+		out := make([]any, len(val))
+		for ind, elem := range val {
+			elem, ok := validNormFast(elem)
+			if !ok {
+				return nil, false
+			}
+			out[ind] = elem
+		}
+		return out, true
+
+	case map[string]any:
+		out := make(map[string]any, len(val))
+		for key, elem := range val {
+			elem, ok := validNormFast(elem)
+			if !ok {
+				return nil, false
+			}
+			out[key] = elem
+		}
+		return out, true
+
+	default:
+		return nil, false
+	}
+}
+
+var validRegexpCache sync.Map
+
+func validRegexp(src string) (*regexp.Regexp, error) {
+	val, ok := validRegexpCache.Load(src)
+	if ok {
+		return val.(*regexp.Regexp), nil
+	}
+
+	out, err := regexp.Compile(src)
+	if err != nil {
+		return nil, fmt.Errorf(`invalid pattern %q: %w`, src, err)
+	}
+
+	validRegexpCache.Store(src, out)
+	return out, nil
+}
+
+var validDuration = regexp.MustCompile(`^P(?:\d+W|(?:\d+Y)?(?:\d+M)?(?:\d+D)?(?:T(?:\d+H)?(?:\d+M)?(?:\d+(?:\.\d+)?S)?)?)$`)
+
+func validStrFormat(format, val string) bool {
+	switch format {
+	case FormatDateTime:
+		_, err := time.Parse(time.RFC3339, val)
+		return err == nil
+
+	case FormatDate:
+		_, err := time.Parse(formatDateIso8601, val)
+		return err == nil
+
+	case FormatTime:
+		_, err := time.Parse(`15:04:05Z07:00`, val)
+		return err == nil
+
+	case FormatDuration:
+		return validDuration.MatchString(val) && val != `P` && !strings.HasSuffix(val, `T`)
+
+	case FormatUuid:
+		return isUuidCanon(val)
+
+	case FormatEmail:
+		addr, err := mail.ParseAddress(val)
+		return err == nil && addr.Address == val
+
+	case `ipv4`:
+		ip := net.ParseIP(val)
+		return ip != nil && ip.To4() != nil && !strings.Contains(val, `:`)
+
+	case `ipv6`:
+		return net.ParseIP(val) != nil && strings.Contains(val, `:`)
+
+	case `uri`:
+		out, err := url.Parse(val)
+		return err == nil && out.IsAbs()
+
+	default:
+		return true
+	}
+}
+
+var (
+	validInt32Min = big.NewRat(math.MinInt32, 1)
+	validInt32Max = big.NewRat(math.MaxInt32, 1)
+	validInt64Min = new(big.Rat).SetInt64(math.MinInt64)
+	validInt64Max = new(big.Rat).SetInt64(math.MaxInt64)
+)
+
+func validIntFormat(format string, val *big.Rat) bool {
+	switch format {
+	case FormatInt32:
+		return !val.IsInt() || (val.Cmp(validInt32Min) >= 0 && val.Cmp(validInt32Max) <= 0)
+	case FormatInt64:
+		return !val.IsInt() || (val.Cmp(validInt64Min) >= 0 && val.Cmp(validInt64Max) <= 0)
+	default:
+		return true
+	}
+}
@@ -0,0 +1,46 @@
+package oas
+
+import "errors"
+
+/*
+Function called by `oas.Walk` for every visited object. `ptr` is a JSON Pointer
+to the object, relative to the walk root. `val` is a non-nil pointer to the
+object, such as `*oas.Op` or `*oas.Schema`, and may be used to modify it in
+place. Modifications of an object are visible to the walk of its children.
+
+Returning `oas.WalkSkip` skips the children of the current object. Returning
+`oas.WalkStop` stops the walk without error. Any other non-nil error stops the
+walk and is returned from `oas.Walk`.
+*/
+type Visitor func(ptr string, val any) error
+
+var (
+	WalkSkip = errors.New(`[oas] skip children`)
+	WalkStop = errors.New(`[oas] stop walking`)
+)
+
+/*
+Visits every OAS object reachable from the given value, parents before
+children, in a deterministic order: struct fields in declaration order, map
+entries sorted by key. The input must be a non-nil pointer, typically `*oas.Doc`.
+"Objects" are values of this package's struct types, such as `oas.Path`,
+`oas.Op`, `oas.Resp`, `oas.MediaType` and `oas.Schema` including all nested
+subschemas. Maps and lists such as `oas.Paths` are traversed but not visited.
+Extensions and values of "any" type, such as examples, are not traversed.
+
+References are not followed: a `oas.Schema` with `.Ref` is visited as-is.
+
+Struct fields which are omitted from the encoding when empty, such as an empty
+`.Comps` of `oas.Doc` or `.Schema` of `oas.MediaType`, are skipped. Map entries
+and list elements are always visited, since an empty schema is meaningful there.
+
+Map values are not addressable, so each map entry is visited via a copy which
+is stored back into the map after walking its children, if it was modified.
+Read-only walks don't write to maps, and may run concurrently on a shared
+document. The visitor must not add or remove entries of maps that are being
+traversed, other than in the children of the current object.
+*/
+func Walk(val any, fun Visitor) error { return walkPtr(val, fun) }
+
+// Shortcut for `oas.Walk` on the document.
+func (self *Doc) Walk(fun Visitor) error { return Walk(self, fun) }
@@ -0,0 +1,113 @@
+package oas
+
+import (
+	"fmt"
+	r "reflect"
+	"strconv"
+)
+
+func walkPtr(val any, fun Visitor) error {
+	rval := r.ValueOf(val)
+	if rval.Kind() != r.Ptr || rval.IsNil() {
+		panic(fmt.Errorf(`[oas] expected non-nil pointer, got %T`, val))
+	}
+
+	err := walker(fun).any(``, rval.Elem())
+	if err == WalkStop {
+		return nil
+	}
+	return err
+}
+
+type walker Visitor
+
+func (self walker) any(ptr string, val r.Value) error {
+	switch val.Kind() {
+	case r.Ptr:
+		if val.IsNil() {
+			return nil
+		}
+		return self.any(ptr, val.Elem())
+
+	case r.Struct:
+		return self.object(ptr, val)
+
+	case r.Slice, r.Array:
+		if !jsonHasOwnStrict(val.Type()) {
+			return nil
+		}
+		for ind := range iter(val.Len()) {
+			err := self.any(ptr+`/`+strconv.Itoa(ind), val.Index(ind))
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+
+	case r.Map:
+		return self.mapping(ptr, val)
+
+	default:
+		return nil
+	}
+}
+
+func (self walker) object(ptr string, val r.Value) error {
+	if !isOwnType(val.Type()) {
+		return nil
+	}
+
+	err := self(ptr, val.Addr().Interface())
+	if err == WalkSkip {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	for _, field := range jsonFieldsOf(val.Type()) {
+		if field.exts {
+			continue
+		}
+
+		fieldPtr := ptr
+		if !field.inline {
+			fieldPtr = ptrAppend(ptr, field.name)
+		}
+
+		fieldVal := val.FieldByIndex(field.index)
+		if !field.inline && field.omitEmpty && fieldVal.Kind() == r.Struct && fieldVal.IsZero() {
+			continue
+		}
+
+		err := self.any(fieldPtr, fieldVal)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (self walker) mapping(ptr string, val r.Value) error {
+	if val.IsNil() || !jsonHasOwnStrict(val.Type().Elem()) {
+		return nil
+	}
+
+	for _, key := range sortedMapKeys(val) {
+		elem := val.MapIndex(key)
+		if !elem.IsValid() {
+			continue
+		}
+
+		tar := r.New(elem.Type()).Elem()
+		tar.Set(elem)
+		err := self.any(ptrAppend(ptr, key.String()), tar)
+		if !r.DeepEqual(elem.Interface(), tar.Interface()) {
+			val.SetMapIndex(key, tar)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
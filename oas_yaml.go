@@ -0,0 +1,51 @@
+package oas
+
+import (
+	"io"
+	r "reflect"
+)
+
+/*
+Encodes an arbitrary value as YAML 1.2, without any 3rd party dependencies.
+Intended for `oas.Doc` and its components, but supports any value composed of
+structs, maps, slices, pointers, interfaces and primitives. Reference:
+
+	https://yaml.org/spec/1.2.2/
+
+Rules:
+
+  - Struct fields are named by their `yaml` tags, falling back on `json` tags,
+    falling back on field names. Fields are encoded in declaration order.
+
+  - Tag flags `omitempty` and `inline` are supported. Embedded structs without
+    a name in the tag are inlined, like in "encoding/json".
+
+  - Map keys are sorted, making the output deterministic.
+
+  - Multi-line strings are encoded as literal block scalars. Other strings are
+    quoted only when they would otherwise be ambiguous.
+
+  - Values implementing `json.Marshaler` are encoded from their JSON
+    representation; values implementing `encoding.TextMarshaler` are encoded
+    as strings.
+*/
+func MarshalYaml(val any) ([]byte, error) {
+	node, err := yamlNodeFrom(r.ValueOf(val))
+	if err != nil {
+		return nil, err
+	}
+
+	var buf yamlWriter
+	buf.root(node)
+	return buf, nil
+}
+
+// Shortcut for writing the output of `oas.MarshalYaml` to the given writer.
+func WriteYaml(out io.Writer, val any) error {
+	chunk, err := MarshalYaml(val)
+	if err != nil {
+		return err
+	}
+	_, err = out.Write(chunk)
+	return err
+}
@@ -0,0 +1,561 @@
+package oas
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/base64"
+	"encoding/json"
+	"math"
+	r "reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+/*
+This file contains the internals of the YAML encoder. Encoding happens in two
+passes: first we convert the input into a tree of nodes, resolving tags,
+omissions and custom marshaling; then we write the tree. The intermediary tree
+is needed because the layout of a node depends on whether its children end up
+empty, which is only known after omissions.
+
+Node types:
+
+	yamlMap -- mapping with ordered keys
+	yamlSeq -- sequence
+	yamlStr -- string, style decided when writing
+	yamlRaw -- pre-rendered plain scalar: null, bool, number
+*/
+
+type yamlMap struct {
+	keys []string
+	vals []any
+}
+
+func (self *yamlMap) add(key string, val any) {
+	self.keys = append(self.keys, key)
+	self.vals = append(self.vals, val)
+}
+
+type yamlSeq []any
+
+type yamlStr string
+
+type yamlRaw string
+
+const yamlNull = yamlRaw(`null`)
+
+var typeJsonNumber = r.TypeOf(json.Number(``))
+
+func yamlNodeFrom(val r.Value) (any, error) {
+	if !val.IsValid() {
+		return yamlNull, nil
+	}
+
+	typ := val.Type()
+
+	if typ.Kind() == r.Interface {
+		if val.IsNil() {
+			return yamlNull, nil
+		}
+		return yamlNodeFrom(val.Elem())
+	}
+
+	if typ == typeJsonNumber {
+		return yamlRaw(val.String()), nil
+	}
+
+	if typ.Implements(ifaceJsonMarshaler) && !isOwnType(typeDeref(typ)) {
+		if typ.Kind() == r.Ptr && val.IsNil() {
+			return yamlNull, nil
+		}
+		return yamlNodeFromJson(val.Interface().(json.Marshaler))
+	}
+
+	if typ.Implements(ifaceTextMarshaler) {
+		if typ.Kind() == r.Ptr && val.IsNil() {
+			return yamlNull, nil
+		}
+		chunk, err := toText(val.Interface().(encoding.TextMarshaler))
+		if err != nil {
+			return nil, err
+		}
+		return yamlStr(chunk), nil
+	}
+
+	switch typ.Kind() {
+	case r.Ptr:
+		if val.IsNil() {
+			return yamlNull, nil
+		}
+		return yamlNodeFrom(val.Elem())
+
+	case r.Bool:
+		return yamlRaw(strconv.FormatBool(val.Bool())), nil
+
+	case r.Int8, r.Int16, r.Int32, r.Int64, r.Int:
+		return yamlRaw(strconv.FormatInt(val.Int(), 10)), nil
+
+	case r.Uint8, r.Uint16, r.Uint32, r.Uint64, r.Uint, r.Uintptr:
+		return yamlRaw(strconv.FormatUint(val.Uint(), 10)), nil
+
+	case r.Float32, r.Float64:
+		return yamlFloat(val.Float(), typ.Bits()), nil
+
+	case r.String:
+		return yamlStr(val.String()), nil
+
+	case r.Slice:
+		if val.IsNil() {
+			return yamlNull, nil
+		}
+		if typ.Elem().Kind() == r.Uint8 {
+			return yamlStr(base64.StdEncoding.EncodeToString(val.Bytes())), nil
+		}
+		return yamlSeqFrom(val)
+
+	case r.Array:
+		return yamlSeqFrom(val)
+
+	case r.Map:
+		if val.IsNil() {
+			return yamlNull, nil
+		}
+		var out yamlMap
+		return out, yamlMapInto(&out, val)
+
+	case r.Struct:
+		if typ == typeSchema {
+			ptr := schemaBoolOf(val)
+			if ptr != nil {
+				return yamlRaw(strconv.FormatBool(*ptr)), nil
+			}
+		}
+
+		var out yamlMap
+		return out, yamlStructInto(&out, val)
+
+	default:
+		return nil, errYamlUnsupported(typ)
+	}
+}
+
+func yamlNodeFromJson(val json.Marshaler) (any, error) {
+	chunk, err := toJson(val)
+	if err != nil {
+		return nil, err
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(chunk))
+	dec.UseNumber()
+
+	var out any
+	err = dec.Decode(&out)
+	if err != nil {
+		return nil, err
+	}
+	return yamlNodeFrom(r.ValueOf(out))
+}
+
+func yamlFloat(val float64, bits int) yamlRaw {
+	if math.IsNaN(val) {
+		return `.nan`
+	}
+	if math.IsInf(val, 1) {
+		return `.inf`
+	}
+	if math.IsInf(val, -1) {
+		return `-.inf`
+	}
+	return yamlRaw(strconv.FormatFloat(val, 'g', -1, bits))
+}
+
+func yamlSeqFrom(val r.Value) (any, error) {
+	out := make(yamlSeq, val.Len())
+	for ind := range out {
+		node, err := yamlNodeFrom(val.Index(ind))
+		if err != nil {
+			return nil, err
+		}
+		out[ind] = node
+	}
+	return out, nil
+}
+
+func yamlMapInto(out *yamlMap, val r.Value) error {
+	keys := val.MapKeys()
+	strs := make([]string, len(keys))
+
+	for ind, key := range keys {
+		str, err := yamlMapKey(key)
+		if err != nil {
+			return err
+		}
+		strs[ind] = str
+	}
+
+	inds := make([]int, len(keys))
+	for ind := range inds {
+		inds[ind] = ind
+	}
+	sort.Slice(inds, func(one, two int) bool { return strs[inds[one]] < strs[inds[two]] })
+
+	for _, ind := range inds {
+		node, err := yamlNodeFrom(val.MapIndex(keys[ind]))
+		if err != nil {
+			return err
+		}
+		out.add(strs[ind], node)
+	}
+	return nil
+}
+
+func yamlMapKey(val r.Value) (string, error) {
+	switch val.Kind() {
+	case r.String:
+		return val.String(), nil
+	case r.Int8, r.Int16, r.Int32, r.Int64, r.Int:
+		return strconv.FormatInt(val.Int(), 10), nil
+	case r.Uint8, r.Uint16, r.Uint32, r.Uint64, r.Uint, r.Uintptr:
+		return strconv.FormatUint(val.Uint(), 10), nil
+	default:
+		return ``, errYamlUnsupported(val.Type())
+	}
+}
+
+func yamlStructInto(out *yamlMap, val r.Value) error {
+	typ := val.Type()
+
+	for ind := range iter(typ.NumField()) {
+		field := typ.Field(ind)
+		tag := yamlTagOf(field)
+		if tag.skip {
+			continue
+		}
+
+		fieldVal := val.Field(ind)
+
+		if tag.inline || (field.Anonymous && tag.name == ``) {
+			if fieldVal.Kind() == r.Ptr {
+				if fieldVal.IsNil() {
+					continue
+				}
+				fieldVal = fieldVal.Elem()
+			}
+
+			switch fieldVal.Kind() {
+			case r.Struct:
+				err := yamlStructInto(out, fieldVal)
+				if err != nil {
+					return err
+				}
+				continue
+
+			case r.Map:
+				err := yamlMapInto(out, fieldVal)
+				if err != nil {
+					return err
+				}
+				continue
+			}
+		}
+
+		if !isPublic(field.PkgPath) {
+			continue
+		}
+		var side r.Value
+		index := sideExtsIndex(typ, field)
+		if index != nil {
+			side = val.FieldByIndex(index)
+		}
+		hasSide := side.IsValid() && side.Len() > 0
+
+		if tag.omitEmpty && isValueEmpty(fieldVal) && !hasSide {
+			continue
+		}
+
+		node, err := yamlNodeFrom(fieldVal)
+		if err != nil {
+			return err
+		}
+
+		if fieldVal.Kind() == r.Map && fieldVal.IsNil() {
+			node = yamlMap{}
+		}
+
+		if hasSide {
+			var sided yamlMap
+			err := yamlMapInto(&sided, fieldVal)
+			if err == nil {
+				err = yamlMapInto(&sided, side)
+			}
+			if err != nil {
+				return err
+			}
+			node = sided
+		}
+
+		name := tag.name
+		if name == `` {
+			name = field.Name
+		}
+		out.add(name, node)
+	}
+	return nil
+}
+
+type yamlTag struct {
+	name      string
+	omitEmpty bool
+	inline    bool
+	skip      bool
+}
+
+func yamlTagOf(field r.StructField) (out yamlTag) {
+	tag, ok := field.Tag.Lookup(`yaml`)
+	if !ok {
+		tag = field.Tag.Get(`json`)
+	}
+
+	if tag == `-` {
+		out.skip = true
+		return
+	}
+
+	opts := strings.Split(tag, `,`)
+	out.name = opts[0]
+
+	for _, opt := range opts[1:] {
+		switch opt {
+		case `omitempty`:
+			out.omitEmpty = true
+		case `inline`:
+			out.inline = true
+		}
+	}
+	return
+}
+
+type yamlWriter []byte
+
+func (self *yamlWriter) root(node any) {
+	switch node := node.(type) {
+	case yamlMap:
+		if len(node.keys) > 0 {
+			self.mapping(node, 0, false)
+			return
+		}
+	case yamlSeq:
+		if len(node) > 0 {
+			self.sequence(node, 0, false)
+			return
+		}
+	case yamlStr:
+		self.string(yamlScalar(string(node)))
+		self.byte('\n')
+		return
+	}
+	self.scalar(node, 0)
+}
+
+func (self *yamlWriter) mapping(node yamlMap, ind int, inline bool) {
+	for pos, key := range node.keys {
+		if pos > 0 || !inline {
+			self.indent(ind)
+		}
+		self.string(yamlScalar(key))
+		self.byte(':')
+		self.value(node.vals[pos], ind)
+	}
+}
+
+func (self *yamlWriter) sequence(node yamlSeq, ind int, inline bool) {
+	for pos, val := range node {
+		if pos > 0 || !inline {
+			self.indent(ind)
+		}
+		self.byte('-')
+		self.item(val, ind)
+	}
+}
+
+// Writes a mapping value, after the key and colon.
+func (self *yamlWriter) value(node any, ind int) {
+	switch node := node.(type) {
+	case yamlMap:
+		if len(node.keys) > 0 {
+			self.byte('\n')
+			self.mapping(node, ind+2, false)
+			return
+		}
+	case yamlSeq:
+		if len(node) > 0 {
+			self.byte('\n')
+			self.sequence(node, ind+2, false)
+			return
+		}
+	}
+	self.byte(' ')
+	self.scalar(node, ind+2)
+}
+
+// Writes a sequence item, after the dash.
+func (self *yamlWriter) item(node any, ind int) {
+	self.byte(' ')
+
+	switch node := node.(type) {
+	case yamlMap:
+		if len(node.keys) > 0 {
+			self.mapping(node, ind+2, true)
+			return
+		}
+	case yamlSeq:
+		if len(node) > 0 {
+			self.sequence(node, ind+2, true)
+			return
+		}
+	}
+	self.scalar(node, ind+2)
+}
+
+/*
+Writes a scalar or an empty collection, followed by a newline. Multi-line
+strings become literal block scalars, with content at the given indentation.
+*/
+func (self *yamlWriter) scalar(node any, ind int) {
+	switch node := node.(type) {
+	case yamlMap:
+		self.string(`{}`)
+	case yamlSeq:
+		self.string(`[]`)
+	case yamlRaw:
+		self.string(string(node))
+	case yamlStr:
+		if yamlIsBlock(string(node)) {
+			self.block(string(node), ind)
+			return
+		}
+		self.string(yamlScalar(string(node)))
+	}
+	self.byte('\n')
+}
+
+func (self *yamlWriter) block(val string, ind int) {
+	body := strings.TrimRight(val, "\n")
+	trail := len(val) - len(body)
+
+	self.byte('|')
+	switch {
+	case trail == 0:
+		self.byte('-')
+	case trail > 1:
+		self.byte('+')
+	}
+	self.byte('\n')
+
+	for _, line := range strings.Split(body, "\n") {
+		if line == `` {
+			self.byte('\n')
+			continue
+		}
+		self.indent(ind)
+		self.string(line)
+		self.byte('\n')
+	}
+
+	for ; trail > 1; trail-- {
+		self.byte('\n')
+	}
+}
+
+func (self *yamlWriter) indent(ind int) {
+	for ; ind > 0; ind-- {
+		self.byte(' ')
+	}
+}
+
+func (self *yamlWriter) byte(val byte)     { *self = append(*self, val) }
+func (self *yamlWriter) string(val string) { *self = append(*self, val...) }
+
+/*
+True if the string can be represented as a literal block scalar without an
+explicit indentation indicator. The first non-empty line must not begin with
+whitespace, since the parser would consider it indentation.
+*/
+func yamlIsBlock(val string) bool {
+	if !strings.Contains(val, "\n") || !yamlIsPrintable(val, true) {
+		return false
+	}
+
+	for _, line := range strings.Split(val, "\n") {
+		if line != `` {
+			return line[0] != ' ' && line[0] != '\t'
+		}
+	}
+	return false
+}
+
+// Returns the string as a plain scalar if possible, otherwise double-quoted.
+func yamlScalar(val string) string {
+	if yamlIsPlain(val) {
+		return val
+	}
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	_ = enc.Encode(val) // Can't fail for strings.
+	return strings.TrimSuffix(buf.String(), "\n")
+}
+
+/*
+Conservative: may quote some strings which could technically be plain. Also
+quotes strings which YAML 1.1 decoders would misinterpret, such as "yes" and
+"off", since such decoders are still common.
+*/
+func yamlIsPlain(val string) bool {
+	if val == `` || yamlReserved[strings.ToLower(val)] {
+		return false
+	}
+
+	if strings.ContainsRune(yamlIndicators, rune(val[0])) || isDecDigit(val[0]) {
+		return false
+	}
+
+	last := val[len(val)-1]
+	if last == ' ' || last == '\t' || last == ':' {
+		return false
+	}
+
+	return !strings.Contains(val, `: `) &&
+		!strings.Contains(val, ` #`) &&
+		yamlIsPrintable(val, false)
+}
+
+const yamlIndicators = "-?:,[]{}#&*!|>'\"%@` \t.+<="
+
+var yamlReserved = map[string]bool{
+	`~`: true, `null`: true,
+	`true`: true, `false`: true,
+	`yes`: true, `no`: true, `y`: true, `n`: true, `on`: true, `off`: true,
+}
+
+func yamlIsPrintable(val string, multiline bool) bool {
+	for _, char := range val {
+		if char == utf8.RuneError || char == '\ufeff' {
+			return false
+		}
+		if char == '\n' && multiline {
+			continue
+		}
+		if char == '\t' || char == ' ' {
+			continue
+		}
+		if !unicode.IsPrint(char) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,254 @@
+/*
+Optional HTTP middleware which validates incoming requests against an
+`oas.Doc`. This is a separate package so that the core package doesn't add
+anything to your HTTP stack. Requests are matched to operations via
+`oas.Paths.Match`. Parameters (path, query, header, cookie) and JSON request
+bodies are validated against their schemas via `oas.Doc.Validate`. Failures
+are reported as RFC 9457 problem details.
+
+Example:
+
+	var doc oas.Doc
+	// ... register routes ...
+	handler = oashttp.Validator{Doc: &doc}.Wrap(handler)
+*/
+package oashttp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/mitranim/oas"
+)
+
+// Content type of RFC 9457 problem details. Same as `oas.ConTypeProblem`.
+const ConTypeProblem = oas.ConTypeProblem
+
+// Default limit of request body size, used when `Validator.MaxBody` is 0.
+const DefaultMaxBody = 1 << 20
+
+/*
+Validates requests against the operations of `.Doc`. The document must not be
+modified while in use.
+*/
+type Validator struct {
+	Doc *oas.Doc
+
+	// Prefix stripped from request paths before matching, such as "/api".
+	Prefix string
+
+	// Maximum size of request bodies. 0 means `DefaultMaxBody`.
+	MaxBody int64
+
+	/**
+	If true, requests which don't match any path are passed to the next handler
+	without validation, instead of being rejected with 404.
+	*/
+	PassUnmatched bool
+
+	/**
+	Called when validation fails, instead of the default response, which is
+	`WriteProblem(rew, err.Problem())`. May be used to customize the response
+	or to log the error.
+	*/
+	OnErr func(rew http.ResponseWriter, req *http.Request, err *Err)
+}
+
+/*
+Returns a handler which validates every request and passes valid requests to
+the given handler. The request body is buffered for validation and replaced,
+allowing the next handler to read it. The matched operation is available to
+the next handler via `MatchFrom`.
+*/
+func (self Validator) Wrap(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(rew http.ResponseWriter, req *http.Request) {
+		match, err := self.Match(req)
+		if err != nil && err.Status == http.StatusNotFound && self.PassUnmatched {
+			next.ServeHTTP(rew, req)
+			return
+		}
+
+		if err == nil {
+			err = self.validate(req, match)
+		}
+		if err != nil {
+			self.fail(rew, req, err)
+			return
+		}
+
+		next.ServeHTTP(rew, req.WithContext(context.WithValue(req.Context(), matchKey{}, match)))
+	})
+}
+
+/*
+Validates the request against the matching operation. Returns nil if valid.
+Like `Validator.Wrap`, replaces the request body with a buffered copy.
+*/
+func (self Validator) Validate(req *http.Request) error {
+	match, err := self.Match(req)
+	if err == nil {
+		err = self.ValidateMatch(req, match)
+	}
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+/*
+Like `Validator.Validate`, but uses the given result of `Validator.Match`,
+avoiding matching the request again.
+*/
+func (self Validator) ValidateMatch(req *http.Request, match Match) *Err {
+	return self.validate(req, match)
+}
+
+/*
+Finds the operation for the request. Returns an error with status 404 if no
+path matches, or 405 if the path doesn't have an operation for the method.
+*/
+func (self Validator) Match(req *http.Request) (Match, *Err) {
+	path := strings.TrimPrefix(req.URL.EscapedPath(), self.Prefix)
+	if !strings.HasPrefix(path, `/`) {
+		path = `/` + path
+	}
+
+	tpl, params, ok := self.Doc.Paths.Match(path)
+	if !ok {
+		return Match{}, &Err{Status: http.StatusNotFound, Detail: `no operation matches path ` + path}
+	}
+
+	item := self.Doc.Paths[tpl]
+	op := item.Op(req.Method)
+	if op == nil {
+		return Match{}, &Err{
+			Status: http.StatusMethodNotAllowed,
+			Detail: fmt.Sprintf(`method %v is not allowed for path %v`, req.Method, tpl),
+			Allow:  item.Methods(),
+		}
+	}
+
+	return Match{Tpl: tpl, Path: item, Op: op, Params: params}, nil
+}
+
+/*
+Converts the raw values of a parameter or header to a JSON value suitable for
+`oas.Doc.Validate`, according to the types allowed by the schema, following
+references. Arrays are split according to the parameter style. Strings which
+can't be converted are kept as-is, letting validation report the mismatch.
+*/
+func (self Validator) Coerce(sch oas.Schema, vals []string, param oas.Param) any {
+	types := self.schemaTypes(sch, 0)
+	if !types[oas.TypeArr] {
+		return coerceScalar(types, vals[0])
+	}
+
+	if len(vals) == 1 {
+		vals = splitParam(param, vals[0])
+	}
+
+	items := self.schemaTypes(self.schemaItems(sch, 0), 0)
+	out := make([]any, len(vals))
+	for ind, val := range vals {
+		out[ind] = coerceScalar(items, val)
+	}
+	return out
+}
+
+// Operation matched to a request.
+type Match struct {
+	Tpl    string
+	Path   oas.Path
+	Op     *oas.Op
+	Params map[string]string
+}
+
+type matchKey struct{}
+
+// Returns the operation matched by `Validator.Wrap`, if any.
+func MatchFrom(ctx context.Context) (Match, bool) {
+	val, ok := ctx.Value(matchKey{}).(Match)
+	return val, ok
+}
+
+/*
+Error returned by `Validator.Validate` and passed to `Validator.OnErr`.
+`.Status` is the suggested HTTP status. `.Errs` lists validation failures.
+*/
+type Err struct {
+	Status int
+	Detail string
+	Allow  []string
+	Errs   []ReqErr
+}
+
+// Implement `error`.
+func (self *Err) Error() string {
+	var buf strings.Builder
+	buf.WriteString(`[oashttp] `)
+	buf.WriteString(self.Detail)
+	for _, val := range self.Errs {
+		buf.WriteString("\n\t")
+		buf.WriteString(val.Error())
+	}
+	return buf.String()
+}
+
+// Converts the error into problem details.
+func (self *Err) Problem() Problem {
+	return Problem{
+		Type:   `about:blank`,
+		Title:  http.StatusText(self.Status),
+		Status: self.Status,
+		Detail: self.Detail,
+		Errors: self.Errs,
+	}
+}
+
+/*
+Validation failure for a part of the request. `.In` is "path", "query",
+"header", "cookie", or "body". `.Name` is the parameter name, empty for the
+body. The embedded `oas.ValidErr` locates the failure within the value and
+the schema, and encodes inline.
+*/
+type ReqErr struct {
+	In   string `json:"in"`
+	Name string `json:"name,omitempty"`
+	oas.ValidErr
+}
+
+// Implement `error`.
+func (self ReqErr) Error() string {
+	if self.Name == `` {
+		return fmt.Sprintf(`%v: %v`, self.In, self.ValidErr.Error())
+	}
+	return fmt.Sprintf(`%v parameter %q: %v`, self.In, self.Name, self.ValidErr.Error())
+}
+
+/*
+RFC 9457 problem details. `.Errors` is an extension member listing validation
+failures.
+*/
+type Problem struct {
+	Type     string   `json:"type,omitempty"`
+	Title    string   `json:"title,omitempty"`
+	Status   int      `json:"status,omitempty"`
+	Detail   string   `json:"detail,omitempty"`
+	Instance string   `json:"instance,omitempty"`
+	Errors   []ReqErr `json:"errors,omitempty"`
+}
+
+// Writes the problem details as JSON, with the status from `.Status`.
+func WriteProblem(rew http.ResponseWriter, val Problem) {
+	status := val.Status
+	if status == 0 {
+		status = http.StatusInternalServerError
+	}
+
+	rew.Header().Set(`Content-Type`, ConTypeProblem)
+	rew.WriteHeader(status)
+	_ = json.NewEncoder(rew).Encode(val)
+}
@@ -0,0 +1,342 @@
+package oashttp
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/mitranim/oas"
+)
+
+func (self Validator) fail(rew http.ResponseWriter, req *http.Request, err *Err) {
+	if self.OnErr != nil {
+		self.OnErr(rew, req, err)
+		return
+	}
+	if len(err.Allow) > 0 {
+		rew.Header().Set(`Allow`, strings.Join(err.Allow, `, `))
+	}
+	WriteProblem(rew, err.Problem())
+}
+
+func (self Validator) validate(req *http.Request, match Match) *Err {
+	var errs []ReqErr
+
+	for _, param := range self.params(match) {
+		errs = append(errs, self.param(req, match, param)...)
+	}
+
+	bodyErrs, err := self.body(req, match.Op)
+	if err != nil {
+		return err
+	}
+	errs = append(errs, bodyErrs...)
+
+	if len(errs) > 0 {
+		return &Err{Status: http.StatusBadRequest, Detail: `request validation failed`, Errs: errs}
+	}
+	return nil
+}
+
+/*
+Parameters of the operation, including path-level parameters, with references
+resolved. Operation-level parameters override path-level ones.
+*/
+func (self Validator) params(match Match) (out []oas.Param) {
+	index := map[string]int{}
+	for _, list := range [][]oas.Param{match.Path.Params, match.Op.Params} {
+		for _, param := range list {
+			if param.Ref != `` {
+				val, err := oas.ResolveAs[oas.Param](self.Doc, param.Ref)
+				if err != nil {
+					continue
+				}
+				param = val
+			}
+
+			key := param.In + `:` + param.Name
+			ind, ok := index[key]
+			if ok {
+				out[ind] = param
+				continue
+			}
+			index[key] = len(out)
+			out = append(out, param)
+		}
+	}
+	return
+}
+
+func (self Validator) param(req *http.Request, match Match, param oas.Param) []ReqErr {
+	vals := paramVals(req, match, param)
+	if len(vals) == 0 {
+		if param.Requ || param.In == oas.InPath {
+			return []ReqErr{paramErr(param, oas.ValidErr{Msg: `missing required parameter`})}
+		}
+		return nil
+	}
+
+	var val any
+	var sch oas.Schema
+
+	if param.Schema != nil {
+		sch = *param.Schema
+		val = self.Coerce(sch, vals, param)
+	} else {
+		// Parameters may use "content" instead of "schema".
+		for key, media := range param.Cont {
+			if !oas.IsConTypeJson(key) {
+				continue
+			}
+			sch = media.Schema
+			var err error
+			val, err = jsonParse(vals[0])
+			if err != nil {
+				return []ReqErr{paramErr(param, oas.ValidErr{Msg: err.Error()})}
+			}
+			break
+		}
+	}
+
+	return validErrs(param.In, param.Name, self.Doc.Validate(sch, val))
+}
+
+func paramVals(req *http.Request, match Match, param oas.Param) []string {
+	switch param.In {
+	case oas.InPath:
+		val, ok := match.Params[param.Name]
+		if ok {
+			return []string{val}
+		}
+	case oas.InQuery:
+		return req.URL.Query()[param.Name]
+	case oas.InHeader:
+		return req.Header.Values(param.Name)
+	case oas.InCookie:
+		val, err := req.Cookie(param.Name)
+		if err == nil {
+			return []string{val.Value}
+		}
+	}
+	return nil
+}
+
+func splitParam(param oas.Param, val string) []string {
+	switch paramStyle(param) {
+	case `spaceDelimited`:
+		return strings.Split(val, ` `)
+	case `pipeDelimited`:
+		return strings.Split(val, `|`)
+	case `form`:
+		if paramExplode(param) {
+			return []string{val}
+		}
+	}
+	return strings.Split(val, `,`)
+}
+
+// Default styles: "form" for query and cookie, "simple" for path and header.
+func paramStyle(param oas.Param) string {
+	if param.Style != `` {
+		return param.Style
+	}
+	if param.In == oas.InQuery || param.In == oas.InCookie {
+		return `form`
+	}
+	return `simple`
+}
+
+// "explode" defaults to true for the "form" style and to false otherwise.
+func paramExplode(param oas.Param) bool {
+	if param.Explode != nil {
+		return *param.Explode
+	}
+	return paramStyle(param) == `form`
+}
+
+func coerceScalar(types map[string]bool, val string) any {
+	if (types[oas.TypeInt] || types[oas.TypeNum]) && isJsonNumber(val) {
+		return json.Number(val)
+	}
+	if types[oas.TypeBool] && (val == `true` || val == `false`) {
+		return val == `true`
+	}
+	if types[oas.TypeNull] && val == `null` && !types[oas.TypeStr] {
+		return nil
+	}
+	return val
+}
+
+func isJsonNumber(val string) bool {
+	return val != `` && (val[0] == '-' || (val[0] >= '0' && val[0] <= '9')) && json.Valid([]byte(val))
+}
+
+// Set of types allowed by the schema, following references and compositions.
+func (self Validator) schemaTypes(sch oas.Schema, depth int) map[string]bool {
+	out := map[string]bool{}
+	self.schemaEach(sch, depth, func(sch oas.Schema) bool {
+		for _, typ := range sch.Type {
+			out[typ] = true
+		}
+		return false
+	})
+	return out
+}
+
+// Items schema of an array schema, following references and compositions.
+func (self Validator) schemaItems(sch oas.Schema, depth int) (out oas.Schema) {
+	self.schemaEach(sch, depth, func(sch oas.Schema) bool {
+		if sch.Items != nil {
+			out = *sch.Items
+			return true
+		}
+		return false
+	})
+	return
+}
+
+/*
+Calls the function for the schema and its subschemas in `allOf`, `anyOf` and
+`oneOf`, following references, until the function returns true.
+*/
+func (self Validator) schemaEach(sch oas.Schema, depth int, fun func(oas.Schema) bool) bool {
+	if depth > 32 {
+		return false
+	}
+
+	if sch.Ref != `` {
+		val, err := oas.ResolveAs[oas.Schema](self.Doc, sch.Ref)
+		if err == nil && self.schemaEach(val, depth+1, fun) {
+			return true
+		}
+	}
+
+	if fun(sch) {
+		return true
+	}
+
+	for _, list := range [][]oas.Schema{sch.AllOf, sch.AnyOf, sch.OneOf} {
+		for _, val := range list {
+			if self.schemaEach(val, depth+1, fun) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (self Validator) body(req *http.Request, op *oas.Op) ([]ReqErr, *Err) {
+	if op.ReqBody == nil {
+		return nil, nil
+	}
+
+	body := *op.ReqBody
+	if body.Ref != `` {
+		val, err := oas.ResolveAs[oas.Body](self.Doc, body.Ref)
+		if err != nil {
+			return nil, &Err{Status: http.StatusInternalServerError, Detail: err.Error()}
+		}
+		body = val
+	}
+
+	src, err := self.readBody(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(src) == 0 {
+		if body.Requ {
+			return []ReqErr{{In: `body`, ValidErr: oas.ValidErr{Msg: `missing required request body`}}}, nil
+		}
+		return nil, nil
+	}
+
+	conType := req.Header.Get(`Content-Type`)
+	_, media, ok := body.Cont.Match(conType)
+	if !ok {
+		return nil, &Err{
+			Status: http.StatusUnsupportedMediaType,
+			Detail: fmt.Sprintf(`unsupported content type %q`, conType),
+		}
+	}
+
+	if !oas.IsConTypeJson(conType) {
+		return nil, nil
+	}
+
+	val, parseErr := jsonParse(string(src))
+	if parseErr != nil {
+		return []ReqErr{{In: `body`, ValidErr: oas.ValidErr{Msg: parseErr.Error()}}}, nil
+	}
+	return validErrs(`body`, ``, self.Doc.Validate(media.Schema, val)), nil
+}
+
+// Reads and replaces the request body, enforcing the size limit.
+func (self Validator) readBody(req *http.Request) ([]byte, *Err) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, nil
+	}
+
+	limit := self.MaxBody
+	if limit == 0 {
+		limit = DefaultMaxBody
+	}
+
+	src, err := io.ReadAll(io.LimitReader(req.Body, limit+1))
+	_ = req.Body.Close()
+	if err != nil {
+		return nil, &Err{Status: http.StatusBadRequest, Detail: `failed to read request body: ` + err.Error()}
+	}
+	if int64(len(src)) > limit {
+		return nil, &Err{
+			Status: http.StatusRequestEntityTooLarge,
+			Detail: fmt.Sprintf(`request body exceeds %v bytes`, limit),
+		}
+	}
+
+	req.Body = io.NopCloser(bytes.NewReader(src))
+	return src, nil
+}
+
+func jsonParse(src string) (out any, err error) {
+	dec := json.NewDecoder(strings.NewReader(src))
+	dec.UseNumber()
+	err = dec.Decode(&out)
+	if err == nil {
+		_, err = dec.Token()
+		if err == io.EOF {
+			return out, nil
+		}
+		if err == nil {
+			err = errors.New(`unexpected data after JSON value`)
+		}
+	}
+	if err != nil {
+		err = fmt.Errorf(`invalid JSON: %w`, err)
+	}
+	return
+}
+
+func validErrs(in, name string, err error) (out []ReqErr) {
+	if err == nil {
+		return nil
+	}
+
+	var errs oas.ValidErrs
+	if !errors.As(err, &errs) {
+		return []ReqErr{{In: in, Name: name, ValidErr: oas.ValidErr{Msg: err.Error()}}}
+	}
+
+	for _, val := range errs {
+		out = append(out, ReqErr{In: in, Name: name, ValidErr: val})
+	}
+	return
+}
+
+func paramErr(param oas.Param, err oas.ValidErr) ReqErr {
+	return ReqErr{In: param.In, Name: param.Name, ValidErr: err}
+}
@@ -0,0 +1,162 @@
+package oashttp
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	r "reflect"
+	"strings"
+	"testing"
+
+	"github.com/mitranim/oas"
+)
+
+type User struct {
+	Id   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+func testDoc() *oas.Doc {
+	var doc oas.Doc
+	doc.Comps.Params = oas.Params{
+		`Id`: {Name: `id`, In: oas.InPath, Head: oas.Head{Requ: true, Schema: &oas.Schema{Type: []string{oas.TypeInt}, Min: floatPtr(1)}}},
+	}
+	doc.Route(`/users/{id}`, http.MethodPut, oas.Op{
+		Params: []oas.Param{
+			{Head: oas.Head{Ref: `#/components/parameters/Id`}},
+			{Name: `dry`, In: oas.InQuery, Head: oas.Head{Schema: &oas.Schema{Type: []string{oas.TypeBool}}}},
+			{Name: `tags`, In: oas.InQuery, Head: oas.Head{Schema: doc.Sch([]string(nil)).Opt()}},
+			{Name: `X-Trace`, In: oas.InHeader, Head: oas.Head{Requ: true, Schema: &oas.Schema{Type: []string{oas.TypeStr}, MinLen: 3}}},
+		},
+		ReqBody: &oas.Body{Requ: true, Cont: oas.MediaTypes{oas.ConTypeJson: {Schema: doc.Sch(User{})}}},
+	})
+	doc.Comps.Schemas[`oashttp.User`].Props[`name`] = oas.Schema{Type: []string{oas.TypeStr}, MinLen: 1}
+	return &doc
+}
+
+func TestValidator(t *testing.T) {
+	var called bool
+	handler := Validator{Doc: testDoc(), Prefix: `/api`}.Wrap(http.HandlerFunc(func(rew http.ResponseWriter, req *http.Request) {
+		called = true
+		match, ok := MatchFrom(req.Context())
+		eq(t, true, ok)
+		eq(t, `/users/{id}`, match.Tpl)
+
+		var user User
+		try(json.NewDecoder(req.Body).Decode(&user))
+		eq(t, User{1, `one`}, user)
+	}))
+
+	serve := func(meth, path, body string, head ...string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(meth, path, strings.NewReader(body))
+		if body != `` {
+			req.Header.Set(`Content-Type`, oas.ConTypeJson)
+		}
+		for ind := 0; ind < len(head); ind += 2 {
+			req.Header.Set(head[ind], head[ind+1])
+		}
+		rew := httptest.NewRecorder()
+		handler.ServeHTTP(rew, req)
+		return rew
+	}
+
+	rew := serve(http.MethodPut, `/api/users/1?dry=true&tags=a&tags=b`, `{"id": 1, "name": "one"}`, `X-Trace`, `abcd`)
+	eq(t, http.StatusOK, rew.Code)
+	eq(t, true, called)
+
+	called = false
+	rew = serve(http.MethodPut, `/api/users/0?dry=maybe`, `{"id": "1", "name": ""}`)
+	eq(t, false, called)
+	eq(t, http.StatusBadRequest, rew.Code)
+	eq(t, ConTypeProblem, rew.Header().Get(`Content-Type`))
+
+	var prob Problem
+	try(json.Unmarshal(rew.Body.Bytes(), &prob))
+	eq(t, `Bad Request`, prob.Title)
+
+	var errs []string
+	for _, val := range prob.Errors {
+		errs = append(errs, val.Error())
+	}
+	eq(t, []string{
+		`path parameter "id": at "" (schema "/minimum"): 0 is less than 1`,
+		`query parameter "dry": at "" (schema "/type"): expected type boolean, got string`,
+		`header parameter "X-Trace": at "" (schema ""): missing required parameter`,
+		`body: at "/id" (schema "/$ref/properties/id/type"): expected type integer, got string`,
+		`body: at "/name" (schema "/$ref/properties/name/minLength"): length 0 is less than 1`,
+	}, errs)
+
+	rew = serve(http.MethodPut, `/api/users/1`, `{`, `X-Trace`, `abcd`)
+	eq(t, http.StatusBadRequest, rew.Code)
+
+	rew = serve(http.MethodPut, `/api/users/1`, ``, `X-Trace`, `abcd`, `Content-Type`, `text/plain`)
+	eq(t, http.StatusBadRequest, rew.Code)
+
+	rew = serve(http.MethodPut, `/api/users/1`, `one`, `X-Trace`, `abcd`, `Content-Type`, `text/plain`)
+	eq(t, http.StatusUnsupportedMediaType, rew.Code)
+
+	rew = serve(http.MethodGet, `/api/users/1`, ``)
+	eq(t, http.StatusMethodNotAllowed, rew.Code)
+	eq(t, `PUT`, rew.Header().Get(`Allow`))
+
+	rew = serve(http.MethodGet, `/api/other`, ``)
+	eq(t, http.StatusNotFound, rew.Code)
+}
+
+func Test_splitParam(t *testing.T) {
+	test := func(param oas.Param, exp []string) {
+		t.Helper()
+		eq(t, exp, splitParam(param, `a,b`))
+	}
+
+	test(oas.Param{In: oas.InQuery}, []string{`a,b`})
+	test(oas.Param{In: oas.InQuery, Head: oas.Head{Style: `form`}}, []string{`a,b`})
+	test(oas.Param{In: oas.InQuery, Head: oas.Head{Style: `form`, Explode: boolPtr(false)}}, []string{`a`, `b`})
+	test(oas.Param{In: oas.InQuery, Head: oas.Head{Explode: boolPtr(false)}}, []string{`a`, `b`})
+	test(oas.Param{In: oas.InCookie, Head: oas.Head{Style: `form`}}, []string{`a,b`})
+	test(oas.Param{In: oas.InPath}, []string{`a`, `b`})
+	test(oas.Param{In: oas.InHeader, Head: oas.Head{Style: `simple`, Explode: boolPtr(true)}}, []string{`a`, `b`})
+}
+
+func TestValidator_OnErr(t *testing.T) {
+	var status int
+	val := Validator{
+		Doc:           testDoc(),
+		PassUnmatched: true,
+		OnErr: func(rew http.ResponseWriter, _ *http.Request, err *Err) {
+			status = err.Status
+			rew.WriteHeader(http.StatusTeapot)
+		},
+	}
+	handler := val.Wrap(http.HandlerFunc(func(rew http.ResponseWriter, _ *http.Request) {
+		rew.WriteHeader(http.StatusAccepted)
+	}))
+
+	rew := httptest.NewRecorder()
+	handler.ServeHTTP(rew, httptest.NewRequest(http.MethodGet, `/other`, nil))
+	eq(t, http.StatusAccepted, rew.Code)
+
+	rew = httptest.NewRecorder()
+	handler.ServeHTTP(rew, httptest.NewRequest(http.MethodPut, `/users/1`, nil))
+	eq(t, http.StatusTeapot, rew.Code)
+	eq(t, http.StatusBadRequest, status)
+
+	err := val.Validate(httptest.NewRequest(http.MethodPut, `/users/1`, nil))
+	eq(t, true, err != nil)
+}
+
+func eq(t testing.TB, exp, act any) {
+	t.Helper()
+	if !r.DeepEqual(exp, act) {
+		t.Fatalf("\nexpected:\n\t%#v\nactual:\n\t%#v", exp, act)
+	}
+}
+
+func try(err error) {
+	if err != nil {
+		panic(err)
+	}
+}
+
+func boolPtr(val bool) *bool        { return &val }
+func floatPtr(val float64) *float64 { return &val }
@@ -0,0 +1,99 @@
+/*
+Mock HTTP server driven by an `oas.Doc`, for developing clients without a
+real server. Works fully offline, with a document built in Go or decoded from
+JSON. Requests are routed to operations by path templates and methods, and
+optionally validated via `oashttp.Validator`. Responses use the declared
+status codes, headers and examples, or examples generated from schemas via
+`oas.Doc.Example`.
+
+Clients can choose the response via the "Prefer" header, like other OpenAPI
+mock servers:
+
+	Prefer: code=404
+	Prefer: example=notFound
+	Prefer: code=404, example=notFound
+
+Example:
+
+	var doc oas.Doc
+	// ... register routes or decode a document ...
+	http.ListenAndServe(`:8080`, oasmock.Mock{Doc: &doc})
+*/
+package oasmock
+
+import (
+	"net/http"
+
+	"github.com/mitranim/oas"
+	"github.com/mitranim/oas/oashttp"
+)
+
+/*
+HTTP handler which serves mock responses for the operations of `.Doc`. The
+document must not be modified while in use.
+*/
+type Mock struct {
+	Doc *oas.Doc
+
+	// Prefix stripped from request paths before matching, such as "/api".
+	Prefix string
+
+	// If true, requests are not validated against the document.
+	NoValidate bool
+
+	// Maximum size of request bodies for validation. 0 means `oashttp.DefaultMaxBody`.
+	MaxBody int64
+
+	// Options for generating examples for media types which don't declare any.
+	ExampleOpt oas.ExampleOpt
+}
+
+/*
+Implement `http.Handler`. Failures are reported as RFC 9457 problem details:
+404 and 405 for unknown operations, 400 and others for invalid requests, 406
+if no declared media type is acceptable, 500 if the document doesn't declare
+the requested response or example.
+*/
+func (self Mock) ServeHTTP(rew http.ResponseWriter, req *http.Request) {
+	valid := oashttp.Validator{Doc: self.Doc, Prefix: self.Prefix, MaxBody: self.MaxBody}
+
+	match, matchErr := valid.Match(req)
+	if matchErr != nil {
+		fail(rew, matchErr)
+		return
+	}
+
+	if !self.NoValidate {
+		err := valid.ValidateMatch(req, match)
+		if err != nil {
+			fail(rew, err)
+			return
+		}
+	}
+
+	res, err := self.respond(req, match)
+	if err != nil {
+		fail(rew, err)
+		return
+	}
+	res.write(rew, req)
+}
+
+/*
+Parsed "Prefer" header. Only the preferences used by mock servers are
+recognized: "code" selects the response by status code, and "example" selects
+a named example from `oas.MediaType.Examples`. Codes outside of the valid
+range 100-599 are ignored.
+*/
+type Prefer struct {
+	Code    int
+	Example string
+}
+
+// Parses the "Prefer" headers of the request. Unknown preferences are ignored.
+func PreferFrom(req *http.Request) (out Prefer) {
+	for _, head := range req.Header.Values(`Prefer`) {
+		out.parse(head)
+	}
+	return
+}
@@ -0,0 +1,351 @@
+package oasmock
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/mitranim/oas"
+	"github.com/mitranim/oas/oashttp"
+)
+
+type mockRes struct {
+	status  int
+	head    http.Header
+	conType string
+	body    []byte
+}
+
+func (self mockRes) write(rew http.ResponseWriter, req *http.Request) {
+	head := rew.Header()
+	for key, vals := range self.head {
+		head[key] = vals
+	}
+	if self.conType != `` {
+		head.Set(`Content-Type`, self.conType)
+	}
+
+	rew.WriteHeader(self.status)
+	if req.Method != http.MethodHead {
+		_, _ = rew.Write(self.body)
+	}
+}
+
+func fail(rew http.ResponseWriter, err *oashttp.Err) {
+	if len(err.Allow) > 0 {
+		rew.Header().Set(`Allow`, strings.Join(err.Allow, `, `))
+	}
+	oashttp.WriteProblem(rew, err.Problem())
+}
+
+func errMock(status int, pat string, args ...any) *oashttp.Err {
+	return &oashttp.Err{Status: status, Detail: fmt.Sprintf(pat, args...)}
+}
+
+func (self Mock) respond(req *http.Request, match oashttp.Match) (mockRes, *oashttp.Err) {
+	prefer := PreferFrom(req)
+
+	status, resp, ok := respSelect(match.Op.Resps, prefer.Code)
+	if !ok {
+		if prefer.Code != 0 {
+			return mockRes{}, errMock(http.StatusInternalServerError, `operation %v %v doesn't declare a response for status %v`, req.Method, match.Tpl, prefer.Code)
+		}
+		return mockRes{}, errMock(http.StatusInternalServerError, `operation %v %v doesn't declare any responses`, req.Method, match.Tpl)
+	}
+
+	if resp.Ref != `` {
+		val, err := oas.ResolveAs[oas.Resp](self.Doc, resp.Ref)
+		if err != nil {
+			return mockRes{}, errMock(http.StatusInternalServerError, `%v`, err)
+		}
+		resp = val
+	}
+
+	out := mockRes{status: status, head: http.Header{}}
+
+	for _, name := range sortedKeys(resp.Head) {
+		// The spec requires ignoring the "Content-Type" header definition.
+		if strings.EqualFold(name, `Content-Type`) {
+			continue
+		}
+		val, ok := self.head(resp.Head[name])
+		if ok {
+			out.head.Set(name, val)
+		}
+	}
+
+	if len(resp.Cont) == 0 {
+		return out, nil
+	}
+
+	key, media, ok := negotiate(resp.Cont, req.Header.Get(`Accept`))
+	if !ok {
+		return mockRes{}, errMock(http.StatusNotAcceptable, `none of the available media types %q is acceptable`, sortedKeys(resp.Cont))
+	}
+
+	val, err := self.example(media, prefer.Example)
+	if err != nil {
+		return mockRes{}, err
+	}
+
+	out.conType, out.body, err = encode(key, val)
+	return out, err
+}
+
+/*
+Uses the first available of: the named example, `.Example`, the first of
+`.Examples`, the example generated from the schema.
+*/
+func (self Mock) example(media oas.MediaType, name string) (any, *oashttp.Err) {
+	if name != `` {
+		val, ok := media.Examples[name]
+		if !ok {
+			return nil, errMock(http.StatusInternalServerError, `example %q is not declared; available examples: %q`, name, sortedKeys(media.Examples))
+		}
+		return self.exampleVal(val)
+	}
+
+	if media.Example != nil {
+		return media.Example, nil
+	}
+
+	if len(media.Examples) > 0 {
+		return self.exampleVal(media.Examples[sortedKeys(media.Examples)[0]])
+	}
+
+	return self.Doc.Example(media.Schema, self.ExampleOpt), nil
+}
+
+func (self Mock) exampleVal(val oas.Example) (any, *oashttp.Err) {
+	if val.Ref != `` {
+		tar, err := oas.ResolveAs[oas.Example](self.Doc, val.Ref)
+		if err != nil {
+			return nil, errMock(http.StatusInternalServerError, `%v`, err)
+		}
+		val = tar
+	}
+	if val.Val == nil && val.ExVal != `` {
+		return nil, errMock(http.StatusInternalServerError, `external example values such as %q are not supported`, val.ExVal)
+	}
+	return val.Val, nil
+}
+
+// Value of a response header, from its examples or schema.
+func (self Mock) head(head oas.Head) (string, bool) {
+	if head.Ref != `` {
+		tar, err := oas.ResolveAs[oas.Head](self.Doc, head.Ref)
+		if err != nil {
+			return ``, false
+		}
+		head = tar
+	}
+
+	var val any
+	switch {
+	case head.Example != nil:
+		val = head.Example
+	case len(head.Examples) > 0:
+		val, _ = self.exampleVal(head.Examples[sortedKeys(head.Examples)[0]])
+	case head.Schema != nil:
+		val = self.Doc.Example(*head.Schema, self.ExampleOpt)
+	default:
+		return ``, false
+	}
+	return headStr(val), true
+}
+
+// Formats a header value in the default "simple" style.
+func headStr(val any) string {
+	switch val := val.(type) {
+	case nil:
+		return ``
+	case string:
+		return val
+	case []any:
+		out := make([]string, len(val))
+		for ind, val := range val {
+			out[ind] = headStr(val)
+		}
+		return strings.Join(out, `,`)
+	}
+
+	out, _ := json.Marshal(val)
+	return string(out)
+}
+
+/*
+Selects the response for the preferred status code, or if none, the default
+success response: the lowest declared 2xx code, "2XX", "default", or the
+lowest other code.
+*/
+func respSelect(resps oas.Resps, code int) (int, oas.Resp, bool) {
+	if isStatus(code) {
+		str := strconv.Itoa(code)
+		for _, key := range []string{str, str[:1] + `XX`, str[:1] + `xx`, `default`} {
+			val, ok := resps[key]
+			if ok {
+				return code, val, true
+			}
+		}
+		return 0, oas.Resp{}, false
+	}
+
+	keys := sortedKeys(resps)
+	for _, key := range keys {
+		status, err := strconv.Atoi(key)
+		if err == nil && status >= 200 && status < 300 {
+			return status, resps[key], true
+		}
+	}
+	for _, key := range []string{`2XX`, `2xx`, `default`} {
+		val, ok := resps[key]
+		if ok {
+			return http.StatusOK, val, true
+		}
+	}
+	for _, key := range keys {
+		status, err := strconv.Atoi(key)
+		if err == nil {
+			return status, resps[key], true
+		}
+	}
+	for _, key := range keys {
+		if len(key) == 3 && strings.EqualFold(key[1:], `XX`) && key[0] >= '1' && key[0] <= '5' {
+			return int(key[0]-'0') * 100, resps[key], true
+		}
+	}
+	return 0, oas.Resp{}, false
+}
+
+// Valid for `http.ResponseWriter.WriteHeader`.
+func isStatus(code int) bool { return code >= 100 && code <= 599 }
+
+type acceptEntry struct {
+	media string
+	q     float64
+}
+
+/*
+Chooses the declared media type most preferred by the "Accept" header. When
+the client accepts anything, JSON is preferred.
+*/
+func negotiate(cont oas.MediaTypes, accept string) (string, oas.MediaType, bool) {
+	keys := sortedKeys(cont)
+
+	for _, entry := range acceptParse(accept) {
+		typ, sub, _ := strings.Cut(entry.media, `/`)
+
+		switch {
+		case entry.media == `*/*`:
+			for _, key := range keys {
+				if oas.IsConTypeJson(key) {
+					return key, cont[key], true
+				}
+			}
+			return keys[0], cont[keys[0]], true
+
+		case sub == `*`:
+			for _, key := range keys {
+				if strings.HasPrefix(strings.ToLower(key), typ+`/`) {
+					return key, cont[key], true
+				}
+			}
+
+		default:
+			key, val, ok := cont.Match(entry.media)
+			if ok {
+				return key, val, true
+			}
+		}
+	}
+	return ``, oas.MediaType{}, false
+}
+
+// Parses the "Accept" header, ordering entries by quality, excluding q=0.
+func acceptParse(src string) (out []acceptEntry) {
+	if strings.TrimSpace(src) == `` {
+		return []acceptEntry{{`*/*`, 1}}
+	}
+
+	for _, part := range strings.Split(src, `,`) {
+		media, params, _ := strings.Cut(part, `;`)
+		entry := acceptEntry{strings.ToLower(strings.TrimSpace(media)), 1}
+		if entry.media == `` {
+			continue
+		}
+
+		for _, param := range strings.Split(params, `;`) {
+			key, val, _ := strings.Cut(param, `=`)
+			if strings.TrimSpace(key) == `q` {
+				q, err := strconv.ParseFloat(strings.TrimSpace(val), 64)
+				if err == nil {
+					entry.q = q
+				}
+			}
+		}
+
+		if entry.q > 0 {
+			out = append(out, entry)
+		}
+	}
+
+	sort.SliceStable(out, func(one, two int) bool { return out[one].q > out[two].q })
+	return
+}
+
+/*
+Encodes the example for the media type. Strings are written as-is for
+non-JSON types. Wildcard media types such as "image/*" are not valid content
+types, so the response uses a generic one.
+*/
+func encode(key string, val any) (string, []byte, *oashttp.Err) {
+	str, isStr := val.(string)
+
+	if strings.Contains(key, `*`) {
+		if isStr {
+			key = `application/octet-stream`
+		} else {
+			key = oas.ConTypeJson
+		}
+	}
+
+	if isStr && !oas.IsConTypeJson(key) {
+		return key, []byte(str), nil
+	}
+
+	out, err := json.Marshal(val)
+	if err != nil {
+		return ``, nil, errMock(http.StatusInternalServerError, `failed to encode example: %v`, err)
+	}
+	return key, out, nil
+}
+
+func (self *Prefer) parse(src string) {
+	for _, part := range strings.Split(src, `,`) {
+		part, _, _ = strings.Cut(part, `;`)
+		key, val, _ := strings.Cut(part, `=`)
+		val = strings.Trim(strings.TrimSpace(val), `"`)
+
+		switch strings.ToLower(strings.TrimSpace(key)) {
+		case `code`:
+			code, err := strconv.Atoi(val)
+			if err == nil && isStatus(code) {
+				self.Code = code
+			}
+		case `example`:
+			self.Example = val
+		}
+	}
+}
+
+func sortedKeys[A any](src map[string]A) []string {
+	out := make([]string, 0, len(src))
+	for key := range src {
+		out = append(out, key)
+	}
+	sort.Strings(out)
+	return out
+}
@@ -0,0 +1,180 @@
+package oasmock
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	r "reflect"
+	"strings"
+	"testing"
+
+	"github.com/mitranim/oas"
+	"github.com/mitranim/oas/oashttp"
+)
+
+type User struct {
+	Id   string `json:"id"`
+	Name string `json:"name"`
+}
+
+func testDoc() *oas.Doc {
+	var doc oas.Doc
+	doc.Comps.Resps = oas.Resps{
+		`NotFound`: {
+			Desc: `Not found.`,
+			Cont: oas.MediaTypes{oashttp.ConTypeProblem: {
+				Examples: oas.Examples{
+					`gone`:    {Val: map[string]any{`title`: `Gone`}},
+					`missing`: {Val: map[string]any{`title`: `Not Found`}},
+				},
+			}},
+		},
+	}
+
+	user := doc.SchemaMedia(User{})
+	doc.MediaExample(&user, `ann`, User{Id: `1`, Name: `Ann`})
+
+	doc.Route(`/users/{id}`, http.MethodGet, oas.Op{
+		Params: []oas.Param{{Name: `id`, In: oas.InPath, Head: oas.Head{Requ: true, Schema: &oas.Schema{Type: []string{oas.TypeStr}, MinLen: 1}}}},
+		Resps: oas.Resps{
+			`200`: {Cont: oas.MediaTypes{oas.ConTypeJson: user, `text/csv`: {Example: "id,name\n1,Ann\n"}}},
+			`404`: {Ref: `#/components/responses/NotFound`},
+		},
+	})
+
+	doc.Route(`/users`, http.MethodPost, oas.Op{
+		ReqBody: &oas.Body{Requ: true, Cont: oas.MediaTypes{oas.ConTypeJson: doc.SchemaMedia(User{})}},
+		Resps: oas.Resps{
+			`201`: {
+				Head: oas.Heads{`Location`: {Requ: true, Schema: &oas.Schema{Type: []string{oas.TypeStr}, Format: `uri`}}},
+				Cont: oas.MediaTypes{oas.ConTypeJson: doc.SchemaMedia(User{})},
+			},
+			`4XX`:     {Desc: `Client error.`},
+			`default`: {Desc: `Unexpected error.`},
+		},
+	})
+
+	doc.Route(`/empty`, http.MethodDelete, oas.Op{})
+	return &doc
+}
+
+func TestMock(t *testing.T) {
+	mock := Mock{Doc: testDoc(), Prefix: `/api`}
+
+	serve := func(meth, path, body string, head ...string) *httptest.ResponseRecorder {
+		t.Helper()
+		req := httptest.NewRequest(meth, path, strings.NewReader(body))
+		if body != `` {
+			req.Header.Set(`Content-Type`, oas.ConTypeJson)
+		}
+		for ind := 0; ind < len(head); ind += 2 {
+			req.Header.Add(head[ind], head[ind+1])
+		}
+		rew := httptest.NewRecorder()
+		mock.ServeHTTP(rew, req)
+		return rew
+	}
+
+	rew := serve(http.MethodGet, `/api/users/1`, ``)
+	eq(t, http.StatusOK, rew.Code)
+	eq(t, oas.ConTypeJson, rew.Header().Get(`Content-Type`))
+	eq(t, `{"id":"1","name":"Ann"}`, rew.Body.String())
+
+	rew = serve(http.MethodGet, `/api/users/1`, ``, `Accept`, `text/html, text/*;q=0.5`)
+	eq(t, `text/csv`, rew.Header().Get(`Content-Type`))
+	eq(t, "id,name\n1,Ann\n", rew.Body.String())
+
+	rew = serve(http.MethodGet, `/api/users/1`, ``, `Accept`, `image/png`)
+	eq(t, http.StatusNotAcceptable, rew.Code)
+	eq(t, oashttp.ConTypeProblem, rew.Header().Get(`Content-Type`))
+
+	rew = serve(http.MethodGet, `/api/users/1`, ``, `Prefer`, `code=404`)
+	eq(t, http.StatusNotFound, rew.Code)
+	eq(t, oashttp.ConTypeProblem, rew.Header().Get(`Content-Type`))
+	eq(t, `{"title":"Gone"}`, rew.Body.String())
+
+	rew = serve(http.MethodGet, `/api/users/1`, ``, `Prefer`, `code=404, example="missing"`)
+	eq(t, `{"title":"Not Found"}`, rew.Body.String())
+
+	rew = serve(http.MethodGet, `/api/users/1`, ``, `Prefer`, `example=other`)
+	eq(t, http.StatusInternalServerError, rew.Code)
+
+	rew = serve(http.MethodGet, `/api/users/1`, ``, `Prefer`, `code=500`)
+	eq(t, http.StatusInternalServerError, rew.Code)
+	eq(t, true, strings.Contains(rew.Body.String(), `doesn't declare a response for status 500`))
+
+	rew = serve(http.MethodPost, `/api/users`, `{"id": "2", "name": "Bob"}`)
+	eq(t, http.StatusCreated, rew.Code)
+	eq(t, `https://example.com`, rew.Header().Get(`Location`))
+	eq(t, `{"id":"string","name":"string"}`, rew.Body.String())
+
+	rew = serve(http.MethodPost, `/api/users`, `{"id": 2}`)
+	eq(t, http.StatusBadRequest, rew.Code)
+
+	var prob oashttp.Problem
+	try(json.Unmarshal(rew.Body.Bytes(), &prob))
+	eq(t, 1, len(prob.Errors))
+
+	rew = serve(http.MethodPost, `/api/users`, `{"id": "2"}`, `Prefer`, `code=409`)
+	eq(t, http.StatusConflict, rew.Code)
+	eq(t, ``, rew.Body.String())
+
+	for _, src := range []string{`code=42`, `code=-5`} {
+		rew = serve(http.MethodPost, `/api/users`, `{"id": "2"}`, `Prefer`, src)
+		eq(t, http.StatusCreated, rew.Code)
+	}
+
+	rew = serve(http.MethodPut, `/api/users`, ``)
+	eq(t, http.StatusMethodNotAllowed, rew.Code)
+	eq(t, `POST`, rew.Header().Get(`Allow`))
+
+	rew = serve(http.MethodDelete, `/api/empty`, ``)
+	eq(t, http.StatusInternalServerError, rew.Code)
+
+	mock.NoValidate = true
+	rew = serve(http.MethodPost, `/api/users`, `{"id": 2}`)
+	eq(t, http.StatusCreated, rew.Code)
+}
+
+func TestPreferFrom(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, `/`, nil)
+	req.Header.Add(`Prefer`, `respond-async, code=404`)
+	req.Header.Add(`Prefer`, `example="one two"; extra`)
+	eq(t, Prefer{Code: 404, Example: `one two`}, PreferFrom(req))
+
+	for _, src := range []string{`code=42`, `code=-5`, `code=600`} {
+		req := httptest.NewRequest(http.MethodGet, `/`, nil)
+		req.Header.Set(`Prefer`, src)
+		eq(t, Prefer{}, PreferFrom(req))
+	}
+}
+
+func Test_respSelect(t *testing.T) {
+	test := func(resps oas.Resps, code, exp int) {
+		t.Helper()
+		act, _, _ := respSelect(resps, code)
+		eq(t, exp, act)
+	}
+
+	test(oas.Resps{`404`: {}, `204`: {}, `201`: {}}, 0, 201)
+	test(oas.Resps{`404`: {}, `default`: {}}, 0, 200)
+	test(oas.Resps{`404`: {}, `400`: {}}, 0, 400)
+	test(oas.Resps{`5XX`: {}}, 0, 500)
+	test(oas.Resps{`5XX`: {}}, 503, 503)
+	test(oas.Resps{`200`: {}}, 503, 0)
+	test(oas.Resps{`201`: {}, `default`: {}}, 42, 201)
+	test(oas.Resps{`201`: {}, `default`: {}}, -5, 201)
+}
+
+func eq(t testing.TB, exp, act any) {
+	t.Helper()
+	if !r.DeepEqual(exp, act) {
+		t.Fatalf("\nexpected:\n\t%#v\nactual:\n\t%#v", exp, act)
+	}
+}
+
+func try(err error) {
+	if err != nil {
+		panic(err)
+	}
+}
@@ -0,0 +1,243 @@
+/*
+Test helpers which check that HTTP responses conform to an `oas.Doc`. Each
+response is matched to the operation of its request via `oashttp.Validator`,
+and its status code, content type, headers and body are compared with the
+responses declared by the operation. This catches handlers which return
+status codes, headers or fields that the document doesn't describe.
+
+Also provides golden-file snapshot tests for documents, see `Golden`.
+
+Example:
+
+	func TestUsers(t *testing.T) {
+		check := oastest.Checker{Doc: &doc, Strict: true}
+		rew := check.Serve(t, handler, httptest.NewRequest(`GET`, `/users/1`, nil))
+		// ... other assertions ...
+	}
+*/
+package oastest
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/mitranim/oas"
+	"github.com/mitranim/oas/oashttp"
+)
+
+/*
+Checks responses against the operations of `.Doc`. The document must not be
+modified while in use.
+*/
+type Checker struct {
+	Doc *oas.Doc
+
+	// Prefix stripped from request paths before matching, such as "/api".
+	Prefix string
+
+	/**
+	If true, responses to requests which don't match any operation are not
+	checked, instead of being reported as errors.
+	*/
+	SkipUnmatched bool
+
+	/**
+	If true, object properties not declared by the response schema are errors.
+	Object schemas used for bodies, properties and array items behave as if
+	they had "unevaluatedProperties": false, unless they, or the schemas they
+	combine via "$ref", "allOf", "anyOf" or "oneOf", specify
+	"additionalProperties", "patternProperties" or "unevaluatedProperties".
+	Properties declared by any of the combined schemas are allowed. Schemas
+	generated from Go types don't restrict additional properties, so without
+	this option, fields missing from the Go type are not detected. The strict
+	variant of the document is built on every check, and reflects changes to
+	the document.
+	*/
+	Strict bool
+}
+
+/*
+Checks the response to the given request. Returns nil if the response
+conforms to the document, otherwise `*oastest.Err`. The response body is read
+and replaced, and may be read again by the caller.
+*/
+func (self Checker) Check(req *http.Request, res *http.Response) error {
+	doc := self.doc()
+	match, matchErr := oashttp.Validator{Doc: doc, Prefix: self.Prefix}.Match(req)
+	if matchErr != nil {
+		if self.SkipUnmatched {
+			return nil
+		}
+		return self.err(req, res, matchErr)
+	}
+
+	body, err := readBody(res)
+	if err != nil {
+		return self.err(req, res, err)
+	}
+
+	errs := self.resp(doc, req, res, match, body)
+	if len(errs) > 0 {
+		return self.err(req, res, errs...)
+	}
+	return nil
+}
+
+// Shortcut for `Checker.Check` with the result of the recorder.
+func (self Checker) CheckRecorder(req *http.Request, rec *httptest.ResponseRecorder) error {
+	return self.Check(req, rec.Result())
+}
+
+/*
+Serves the request via the handler, using `httptest.ResponseRecorder`, and
+reports nonconformance of the response as a test error. Returns the recorder
+for further assertions.
+*/
+func (self Checker) Serve(t testing.TB, han http.Handler, req *http.Request) *httptest.ResponseRecorder {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	han.ServeHTTP(rec, req)
+
+	err := self.CheckRecorder(req, rec)
+	if err != nil {
+		t.Error(err)
+	}
+	return rec
+}
+
+/*
+Returns a handler which checks every response of the given handler and
+reports nonconformance as a test error. Useful with `httptest.NewServer`.
+Responses are buffered in full before being written, so streaming responses
+are delivered at once.
+*/
+func (self Checker) Wrap(t testing.TB, han http.Handler) http.Handler {
+	return http.HandlerFunc(func(rew http.ResponseWriter, req *http.Request) {
+		rec := httptest.NewRecorder()
+		han.ServeHTTP(rec, req)
+
+		err := self.CheckRecorder(req, rec)
+		if err != nil {
+			t.Error(err)
+		}
+
+		head := rew.Header()
+		for key, vals := range rec.Header() {
+			head[key] = vals
+		}
+		rew.WriteHeader(rec.Code)
+		_, _ = io.Copy(rew, rec.Body)
+	})
+}
+
+/*
+Error returned by `Checker.Check`. `.Errs` lists individual failures, which may
+include `oas.ValidErrs` for the body and headers.
+*/
+type Err struct {
+	Meth   string
+	Path   string
+	Status int
+	Errs   []error
+}
+
+// Implement `error`.
+func (self *Err) Error() string {
+	var buf strings.Builder
+	fmt.Fprintf(&buf, `[oastest] response to %v %v with status %v doesn't conform to the document`, self.Meth, self.Path, self.Status)
+	for _, val := range self.Errs {
+		buf.WriteString("\n\t")
+		buf.WriteString(strings.ReplaceAll(val.Error(), "\n", "\n\t"))
+	}
+	return buf.String()
+}
+
+// Implement error unwrapping for `errors.Is` and `errors.As`.
+func (self *Err) Unwrap() []error { return self.Errs }
+
+/*
+Name of the environment variable which makes `Golden` and `GoldenBytes`
+rewrite golden files instead of comparing them. Any non-empty value other than
+"0" or "false" enables updating. Example:
+
+	OAS_UPDATE_GOLDEN=1 go test ./...
+*/
+const EnvUpdate = `OAS_UPDATE_GOLDEN`
+
+/*
+Snapshot test for a document. Encodes the document deterministically and
+compares the result with the golden file at the given path. Files with the
+".yaml" or ".yml" extension are encoded via `oas.MarshalYaml`, other files as
+indented JSON. See `GoldenBytes` for comparing and updating. Usage:
+
+	func TestDocs(t *testing.T) { oastest.Golden(t, `testdata/openapi.json`, &doc) }
+*/
+func Golden(t testing.TB, path string, doc *oas.Doc) {
+	t.Helper()
+
+	src, err := goldenEncode(path, doc)
+	if err != nil {
+		t.Fatalf(`[oastest] failed to encode document for golden file %q: %v`, path, err)
+		return
+	}
+	GoldenBytes(t, path, src)
+}
+
+/*
+Compares the given content with the golden file at the given path, and fails
+the test with a line diff on mismatch. If `IsUpdate` is true, writes the
+content to the file instead, creating parent directories as needed.
+*/
+func GoldenBytes(t testing.TB, path string, act []byte) {
+	t.Helper()
+
+	if IsUpdate() {
+		err := goldenWrite(path, act)
+		if err != nil {
+			t.Fatalf(`[oastest] failed to update golden file %q: %v`, path, err)
+		}
+		return
+	}
+
+	exp, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf(`[oastest] golden file %q doesn't exist; rerun with %v=1 to create it`, path, EnvUpdate)
+		return
+	}
+	if err != nil {
+		t.Fatalf(`[oastest] failed to read golden file %q: %v`, path, err)
+		return
+	}
+
+	if !bytes.Equal(exp, act) {
+		t.Errorf(
+			"[oastest] content doesn't match golden file %q; rerun with %v=1 to update it; diff (-golden +actual):\n%v",
+			path, EnvUpdate, Diff(string(exp), string(act)),
+		)
+	}
+}
+
+// True if golden files should be updated. See `EnvUpdate`.
+func IsUpdate() bool {
+	val := os.Getenv(EnvUpdate)
+	return val != `` && val != `0` && val != `false`
+}
+
+/*
+Returns a line diff between the two strings, with lines removed from the first
+prefixed with "-", lines added in the second prefixed with "+", and up to 3
+lines of unchanged context around each change, prefixed with " ". Each hunk
+starts with a line such as "@@ -10,7 +10,8 @@", like a unified diff. Returns
+an empty string if the inputs are equal.
+*/
+func Diff(prev, next string) string {
+	return diffLines(strings.Split(prev, "\n"), strings.Split(next, "\n"))
+}
@@ -0,0 +1,387 @@
+package oastest
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/mitranim/oas"
+	"github.com/mitranim/oas/oashttp"
+)
+
+func (self Checker) doc() *oas.Doc {
+	if self.Strict {
+		return strictDoc(self.Doc)
+	}
+	return self.Doc
+}
+
+func (self Checker) err(req *http.Request, res *http.Response, errs ...error) *Err {
+	return &Err{Meth: req.Method, Path: req.URL.RequestURI(), Status: res.StatusCode, Errs: errs}
+}
+
+func (self Checker) resp(doc *oas.Doc, req *http.Request, res *http.Response, match oashttp.Match, body []byte) (errs []error) {
+	key, resp, ok := respFind(match.Op.Resps, res.StatusCode)
+	if !ok {
+		return []error{fmt.Errorf(`undocumented status %v`, res.StatusCode)}
+	}
+
+	if resp.Ref != `` {
+		val, err := oas.ResolveAs[oas.Resp](doc, resp.Ref)
+		if err != nil {
+			return []error{fmt.Errorf(`response %q: %w`, key, err)}
+		}
+		resp = val
+	}
+
+	for _, name := range sortedKeys(resp.Head) {
+		errs = append(errs, self.head(doc, res, name, resp.Head[name])...)
+	}
+	return append(errs, self.body(doc, req, res, resp, body)...)
+}
+
+func (self Checker) head(doc *oas.Doc, res *http.Response, name string, head oas.Head) []error {
+	// The spec requires ignoring the "Content-Type" header definition.
+	if strings.EqualFold(name, `Content-Type`) {
+		return nil
+	}
+
+	if head.Ref != `` {
+		val, err := oas.ResolveAs[oas.Head](doc, head.Ref)
+		if err != nil {
+			return []error{fmt.Errorf(`header %q: %w`, name, err)}
+		}
+		head = val
+	}
+
+	vals := res.Header.Values(name)
+	if len(vals) == 0 {
+		if head.Requ {
+			return []error{fmt.Errorf(`missing required header %q`, name)}
+		}
+		return nil
+	}
+	if head.Schema == nil {
+		return nil
+	}
+
+	valid := oashttp.Validator{Doc: doc}
+	param := oas.Param{Head: head, Name: name, In: oas.InHeader}
+	err := doc.Validate(*head.Schema, valid.Coerce(*head.Schema, vals, param))
+	if err != nil {
+		return []error{fmt.Errorf(`header %q: %w`, name, err)}
+	}
+	return nil
+}
+
+func (self Checker) body(doc *oas.Doc, req *http.Request, res *http.Response, resp oas.Resp, body []byte) []error {
+	if len(resp.Cont) == 0 {
+		if len(body) > 0 {
+			return []error{errors.New(`undocumented response body`)}
+		}
+		return nil
+	}
+
+	if len(body) == 0 {
+		if req.Method == http.MethodHead {
+			return nil
+		}
+		return []error{errors.New(`missing response body`)}
+	}
+
+	conType := res.Header.Get(`Content-Type`)
+	_, media, ok := resp.Cont.Match(conType)
+	if !ok {
+		return []error{fmt.Errorf(`undocumented content type %q, expected one of %q`, conType, sortedKeys(resp.Cont))}
+	}
+
+	if !oas.IsConTypeJson(conType) {
+		return nil
+	}
+
+	err := doc.ValidateJson(media.Schema, body)
+	if err != nil {
+		return []error{fmt.Errorf(`body: %w`, err)}
+	}
+	return nil
+}
+
+/*
+Finds the response for the status: exact code, then range such as "2XX", then
+"default". Ranges are matched case-insensitively.
+*/
+func respFind(resps oas.Resps, status int) (string, oas.Resp, bool) {
+	code := fmt.Sprint(status)
+	for _, key := range []string{code, code[:1] + `XX`, code[:1] + `xx`, `default`} {
+		val, ok := resps[key]
+		if ok {
+			return key, val, true
+		}
+	}
+	return ``, oas.Resp{}, false
+}
+
+/*
+Returns a copy of the document where object schemas reject undeclared
+properties at their use sites: media types, properties and array items. The
+restriction is never added to schemas combined via "allOf", "anyOf", "oneOf"
+or referenced by "$ref", because "unevaluatedProperties" in such a subschema
+doesn't see properties evaluated by its siblings. At the use site, it sees
+properties evaluated by all of them. See `Checker.Strict`.
+*/
+func strictDoc(src *oas.Doc) *oas.Doc {
+	doc := src.Clone()
+	use := func(sch *oas.Schema) {
+		if sch != nil && strictCan(src, *sch) {
+			sch.UnevalProps = oas.BoolSchema(false).Opt()
+		}
+	}
+
+	_ = doc.Walk(func(_ string, val any) error {
+		switch val := val.(type) {
+		case *oas.MediaType:
+			use(&val.Schema)
+			use(val.ItemSchema)
+
+		case *oas.Schema:
+			for key, prop := range val.Props {
+				use(&prop)
+				val.Props[key] = prop
+			}
+			for ind := range val.PrefixItems {
+				use(&val.PrefixItems[ind])
+			}
+			use(val.Items)
+			use(val.AddProps)
+		}
+		return nil
+	})
+	return &doc
+}
+
+/*
+True if the schema, combined with the schemas it references or composes,
+declares properties, and doesn't specify how to treat other properties.
+*/
+func strictCan(doc *oas.Doc, sch oas.Schema) bool {
+	props, open := strictProps(doc, sch, map[string]bool{})
+	return props && !open
+}
+
+func strictProps(doc *oas.Doc, sch oas.Schema, seen map[string]bool) (props, open bool) {
+	if sch.AddProps != nil || len(sch.PatProps) > 0 || sch.UnevalProps != nil {
+		return false, true
+	}
+	props = len(sch.Props) > 0
+
+	if sch.Ref != `` && !seen[sch.Ref] {
+		seen[sch.Ref] = true
+		tar, err := oas.ResolveAs[oas.Schema](doc, sch.Ref)
+		if err != nil {
+			return false, true
+		}
+		subProps, subOpen := strictProps(doc, tar, seen)
+		props, open = props || subProps, subOpen
+	}
+
+	for _, list := range [][]oas.Schema{sch.AllOf, sch.AnyOf, sch.OneOf} {
+		for _, sub := range list {
+			subProps, subOpen := strictProps(doc, sub, seen)
+			props, open = props || subProps, open || subOpen
+		}
+	}
+	return
+}
+
+func readBody(res *http.Response) ([]byte, error) {
+	if res.Body == nil {
+		return nil, nil
+	}
+	src, err := io.ReadAll(res.Body)
+	_ = res.Body.Close()
+	res.Body = io.NopCloser(bytes.NewReader(src))
+	if err != nil {
+		return nil, fmt.Errorf(`failed to read response body: %w`, err)
+	}
+	return src, nil
+}
+
+func goldenEncode(path string, doc *oas.Doc) ([]byte, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case `.yaml`, `.yml`:
+		return oas.MarshalYaml(doc)
+	}
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent(``, `  `)
+	err := enc.Encode(doc)
+	return buf.Bytes(), err
+}
+
+func goldenWrite(path string, src []byte) error {
+	err := os.MkdirAll(filepath.Dir(path), os.ModePerm)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, src, 0o666)
+}
+
+// Lines of context around changes in `Diff`.
+const diffContext = 3
+
+/*
+Limit of the size of the table used for finding the longest common
+subsequence. Beyond it, the differing middle parts of the inputs are reported
+as entirely replaced, which is still correct but less readable.
+*/
+const diffMaxCells = 1 << 22
+
+type diffOp struct {
+	kind byte
+	line string
+}
+
+func diffLines(prev, next []string) string {
+	var pre int
+	for pre < len(prev) && pre < len(next) && prev[pre] == next[pre] {
+		pre++
+	}
+
+	var suf int
+	for suf < len(prev)-pre && suf < len(next)-pre && prev[len(prev)-1-suf] == next[len(next)-1-suf] {
+		suf++
+	}
+
+	if pre == len(prev) && pre == len(next) {
+		return ``
+	}
+
+	ops := make([]diffOp, 0, len(prev)+len(next))
+	for _, line := range prev[:pre] {
+		ops = append(ops, diffOp{' ', line})
+	}
+	ops = diffMiddle(ops, prev[pre:len(prev)-suf], next[pre:len(next)-suf])
+	for _, line := range prev[len(prev)-suf:] {
+		ops = append(ops, diffOp{' ', line})
+	}
+	return diffHunks(ops)
+}
+
+// Appends the diff of the inputs, based on their longest common subsequence.
+func diffMiddle(out []diffOp, prev, next []string) []diffOp {
+	if (len(prev)+1)*(len(next)+1) > diffMaxCells {
+		for _, line := range prev {
+			out = append(out, diffOp{'-', line})
+		}
+		for _, line := range next {
+			out = append(out, diffOp{'+', line})
+		}
+		return out
+	}
+
+	// `lens[i][j]` is the LCS length of `prev[i:]` and `next[j:]`.
+	lens := make([][]int32, len(prev)+1)
+	for ind := range lens {
+		lens[ind] = make([]int32, len(next)+1)
+	}
+	for ind := len(prev) - 1; ind >= 0; ind-- {
+		for jnd := len(next) - 1; jnd >= 0; jnd-- {
+			if prev[ind] == next[jnd] {
+				lens[ind][jnd] = lens[ind+1][jnd+1] + 1
+			} else if lens[ind+1][jnd] >= lens[ind][jnd+1] {
+				lens[ind][jnd] = lens[ind+1][jnd]
+			} else {
+				lens[ind][jnd] = lens[ind][jnd+1]
+			}
+		}
+	}
+
+	var ind, jnd int
+	for ind < len(prev) || jnd < len(next) {
+		switch {
+		case ind < len(prev) && jnd < len(next) && prev[ind] == next[jnd]:
+			out = append(out, diffOp{' ', prev[ind]})
+			ind++
+			jnd++
+		case jnd == len(next) || (ind < len(prev) && lens[ind+1][jnd] >= lens[ind][jnd+1]):
+			out = append(out, diffOp{'-', prev[ind]})
+			ind++
+		default:
+			out = append(out, diffOp{'+', next[jnd]})
+			jnd++
+		}
+	}
+	return out
+}
+
+// Renders changes with surrounding context, grouping nearby changes in hunks.
+func diffHunks(ops []diffOp) string {
+	var buf strings.Builder
+
+	for ind := 0; ind < len(ops); {
+		if ops[ind].kind == ' ' {
+			ind++
+			continue
+		}
+
+		start := ind - diffContext
+		if start < 0 {
+			start = 0
+		}
+
+		// Extend the hunk while the next change is within twice the context.
+		end := ind
+		for cur := ind; cur < len(ops) && cur-end <= 2*diffContext; cur++ {
+			if ops[cur].kind != ' ' {
+				end = cur
+			}
+		}
+		end += diffContext + 1
+		if end > len(ops) {
+			end = len(ops)
+		}
+
+		prevStart, nextStart := diffLineNums(ops[:start])
+		prevLen, nextLen := diffLineNums(ops[start:end])
+
+		fmt.Fprintf(&buf, "@@ -%v,%v +%v,%v @@\n", prevStart+1, prevLen, nextStart+1, nextLen)
+		for _, op := range ops[start:end] {
+			buf.WriteByte(op.kind)
+			buf.WriteString(op.line)
+			buf.WriteByte('\n')
+		}
+		ind = end
+	}
+	return buf.String()
+}
+
+// Counts the lines of the previous and next inputs among the given ops.
+func diffLineNums(ops []diffOp) (prev, next int) {
+	for _, op := range ops {
+		if op.kind != '+' {
+			prev++
+		}
+		if op.kind != '-' {
+			next++
+		}
+	}
+	return
+}
+
+func sortedKeys[A any](src map[string]A) []string {
+	out := make([]string, 0, len(src))
+	for key := range src {
+		out = append(out, key)
+	}
+	sort.Strings(out)
+	return out
+}
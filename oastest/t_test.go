@@ -0,0 +1,276 @@
+package oastest
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	r "reflect"
+	"strings"
+	"testing"
+
+	"github.com/mitranim/oas"
+)
+
+type User struct {
+	Id   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+func testDoc() *oas.Doc {
+	var doc oas.Doc
+	resps := doc.RespsOkJson(User{})
+	resps[`4XX`] = oas.Resp{Desc: `Client error.`}
+	resps[`201`] = oas.Resp{
+		Head: oas.Heads{
+			`Location`: {Requ: true, Schema: &oas.Schema{Type: []string{oas.TypeStr}, MinLen: 2}},
+		},
+	}
+	doc.Route(`/users/{id}`, http.MethodGet, oas.Op{Resps: resps})
+	return &doc
+}
+
+func TestChecker(t *testing.T) {
+	var status int
+	var body, conType, loc string
+
+	handler := http.HandlerFunc(func(rew http.ResponseWriter, _ *http.Request) {
+		if conType != `` {
+			rew.Header().Set(`Content-Type`, conType)
+		}
+		if loc != `` {
+			rew.Header().Set(`Location`, loc)
+		}
+		rew.WriteHeader(status)
+		_, _ = rew.Write([]byte(body))
+	})
+
+	check := func(checker Checker, path string) []string {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		err := checker.CheckRecorder(req, rec)
+		if err == nil {
+			return nil
+		}
+
+		var tar *Err
+		eq(t, true, errors.As(err, &tar))
+
+		var out []string
+		for _, val := range tar.Errs {
+			out = append(out, val.Error())
+		}
+		return out
+	}
+
+	checker := Checker{Doc: testDoc()}
+
+	status, conType, body = 200, `application/json; charset=utf-8`, `{"id": 1, "name": "one", "extra": true}`
+	eq(t, []string(nil), check(checker, `/users/1`))
+
+	checker.Strict = true
+	eq(t, []string{
+		`body: [oas] validation failed:` + "\n\t" + `at "/extra" (schema "/unevaluatedProperties"): boolean schema "false" rejects every instance`,
+	}, check(checker, `/users/1`))
+	checker.Strict = false
+
+	status, body = 200, `{"id": "1"}`
+	eq(t, 1, len(check(checker, `/users/1`)))
+
+	status, conType, body = 200, `text/plain`, `one`
+	eq(t, []string{`undocumented content type "text/plain", expected one of ["application/json"]`}, check(checker, `/users/1`))
+
+	status, conType, body = 200, ``, ``
+	eq(t, []string{`missing response body`}, check(checker, `/users/1`))
+
+	status = 404
+	eq(t, []string(nil), check(checker, `/users/1`))
+
+	body = `not found`
+	eq(t, []string{`undocumented response body`}, check(checker, `/users/1`))
+
+	status, body = 500, ``
+	eq(t, []string{`undocumented status 500`}, check(checker, `/users/1`))
+
+	status = 201
+	eq(t, []string{`missing required header "Location"`}, check(checker, `/users/1`))
+
+	loc = `/`
+	eq(t, 1, len(check(checker, `/users/1`)))
+
+	loc = `/users/2`
+	eq(t, []string(nil), check(checker, `/users/1`))
+
+	eq(t, []string{`[oashttp] no operation matches path /other`}, check(checker, `/other`))
+
+	checker.SkipUnmatched = true
+	eq(t, []string(nil), check(checker, `/other`))
+}
+
+func TestChecker_Strict(t *testing.T) {
+	type Ext struct {
+		Code string `json:"code"`
+	}
+	type Team struct {
+		Lead    User   `json:"lead"`
+		Members []User `json:"members"`
+	}
+
+	var doc oas.Doc
+	doc.Route(`/teams`, http.MethodPost, oas.Op{Resps: doc.Responses().
+		Ok(Team{}, ``).
+		Problem(`400`, Ext{}, ``).
+		Done()})
+
+	checker := Checker{Doc: &doc, Strict: true}
+	check := func(status int, body string) error {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodPost, `/teams`, nil)
+		rec := httptest.NewRecorder()
+		if status == 400 {
+			rec.Header().Set(`Content-Type`, oas.ConTypeProblem)
+		} else {
+			rec.Header().Set(`Content-Type`, oas.ConTypeJson)
+		}
+		rec.WriteHeader(status)
+		_, _ = rec.WriteString(body)
+		return checker.CheckRecorder(req, rec)
+	}
+
+	try(check(400, `{"type": "about:blank", "title": "x", "status": 400, "code": "x"}`))
+	errs(t, check(400, `{"title": "x", "other": 1}`), `at "/other" (schema "/unevaluatedProperties")`)
+
+	try(check(200, `{"lead": {"id": 1, "name": "a"}, "members": [{"id": 2, "name": "b"}]}`))
+	errs(t, check(200, `{"lead": {"id": 1, "extra": 1}, "members": []}`), `at "/lead/extra"`)
+	errs(t, check(200, `{"lead": {"id": 1}, "members": [{"id": 2, "extra": 1}]}`), `at "/members/0/extra"`)
+}
+
+func errs(t testing.TB, err error, msg string) {
+	t.Helper()
+	if err == nil || !strings.Contains(err.Error(), msg) {
+		t.Fatalf("expected error containing %q, got %v", msg, err)
+	}
+}
+
+func TestChecker_Wrap(t *testing.T) {
+	checker := Checker{Doc: testDoc()}
+	inner := &fakeTB{TB: t}
+
+	handler := checker.Wrap(inner, http.HandlerFunc(func(rew http.ResponseWriter, _ *http.Request) {
+		rew.Header().Set(`X-Custom`, `one`)
+		rew.WriteHeader(http.StatusTeapot)
+		_, _ = rew.Write([]byte(`short and stout`))
+	}))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, `/users/1`, nil))
+
+	eq(t, http.StatusTeapot, rec.Code)
+	eq(t, `one`, rec.Header().Get(`X-Custom`))
+	eq(t, `short and stout`, rec.Body.String())
+	eq(t, 1, len(inner.errs))
+	eq(t, true, strings.Contains(inner.errs[0], `undocumented response body`))
+}
+
+func TestGolden(t *testing.T) {
+	doc := testDoc()
+	path := filepath.Join(t.TempDir(), `nested`, `openapi.json`)
+
+	inner := &fakeTB{TB: t}
+	Golden(inner, path, doc)
+	eq(t, 1, len(inner.errs))
+	eq(t, true, strings.Contains(inner.errs[0], `doesn't exist; rerun with OAS_UPDATE_GOLDEN=1`))
+
+	t.Setenv(EnvUpdate, `1`)
+	inner = &fakeTB{TB: t}
+	Golden(inner, path, doc)
+	eq(t, []string(nil), inner.errs)
+
+	src, err := os.ReadFile(path)
+	try(err)
+	eq(t, true, strings.HasPrefix(string(src), "{\n  \"paths\": {\n"))
+	eq(t, true, strings.HasSuffix(string(src), "}\n"))
+
+	t.Setenv(EnvUpdate, `false`)
+	inner = &fakeTB{TB: t}
+	Golden(inner, path, testDoc())
+	eq(t, []string(nil), inner.errs)
+
+	doc.Paths[`/users/{id}`].Get.Desc = `Gets a user.`
+	Golden(inner, path, doc)
+	eq(t, 1, len(inner.errs))
+	eq(t, true, strings.Contains(inner.errs[0], "+        \"description\": \"Gets a user.\",\n"))
+
+	path = filepath.Join(filepath.Dir(path), `openapi.yaml`)
+	t.Setenv(EnvUpdate, `1`)
+	Golden(inner, path, doc)
+	src, err = os.ReadFile(path)
+	try(err)
+	eq(t, true, strings.HasPrefix(string(src), "paths:\n"))
+}
+
+func TestDiff(t *testing.T) {
+	eq(t, ``, Diff("one\ntwo", "one\ntwo"))
+
+	eq(t, "@@ -1,3 +1,3 @@\n one\n-two\n+TWO\n three\n", Diff("one\ntwo\nthree", "one\nTWO\nthree"))
+
+	prev := "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n13\n14\n15\n16"
+	next := "1\n2\n3\n4\nfive\n6\n7\n8\n9\n10\n11\n12\n13\n15\n16\n17"
+	eq(t, strings.Join([]string{
+		`@@ -2,7 +2,7 @@`,
+		` 2`,
+		` 3`,
+		` 4`,
+		`-5`,
+		`+five`,
+		` 6`,
+		` 7`,
+		` 8`,
+		`@@ -11,6 +11,6 @@`,
+		` 11`,
+		` 12`,
+		` 13`,
+		`-14`,
+		` 15`,
+		` 16`,
+		`+17`,
+		``,
+	}, "\n"), Diff(prev, next))
+}
+
+func TestErr(t *testing.T) {
+	err := &Err{Meth: `GET`, Path: `/one`, Status: 200, Errs: []error{errors.New("one\ntwo")}}
+	eq(t, true, strings.HasPrefix(err.Error(), `[oastest] response to GET /one with status 200`))
+	eq(t, true, strings.HasSuffix(err.Error(), "\n\tone\n\ttwo"))
+}
+
+type fakeTB struct {
+	testing.TB
+	errs []string
+}
+
+func (self *fakeTB) Error(args ...any) { self.errs = append(self.errs, fmt.Sprint(args...)) }
+
+func (self *fakeTB) Errorf(pat string, args ...any) {
+	self.errs = append(self.errs, fmt.Sprintf(pat, args...))
+}
+
+func (self *fakeTB) Fatalf(pat string, args ...any) { self.Errorf(pat, args...) }
+
+func eq(t testing.TB, exp, act any) {
+	t.Helper()
+	if !r.DeepEqual(exp, act) {
+		t.Fatalf("\nexpected:\n\t%#v\nactual:\n\t%#v", exp, act)
+	}
+}
+
+func try(err error) {
+	if err != nil {
+		panic(err)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"strings"
 	"testing"
 	"time"
 
@@ -219,6 +220,12 @@ func outerSchemas() Schemas {
 	}
 }
 
+func getPersons() {}
+
+type opHandlers struct{}
+
+func (opHandlers) CreatePerson() {}
+
 func eq(t testing.TB, exp, act interface{}) {
 	t.Helper()
 	if !r.DeepEqual(exp, act) {
@@ -247,10 +254,29 @@ func try(err error) {
 	}
 }
 
+// Asserts that the function panics with an error message containing the substring.
+func panics(t testing.TB, msg string, fun func()) {
+	t.Helper()
+	val := catch(fun)
+	if val == nil {
+		t.Fatalf(`expected panic with %q, got none`, msg)
+	}
+	if !strings.Contains(fmt.Sprint(val), msg) {
+		t.Fatalf("expected panic with %q, got:\n\t%v", msg, val)
+	}
+}
+
+func catch(fun func()) (out any) {
+	defer func() { out = recover() }()
+	fun()
+	return
+}
+
 func writeFile(path, body string) {
 	try(os.WriteFile(path, []byte(body), os.ModePerm))
 }
 
-func intPtr(val int) *int          { return &val }
-func stringPtr(val string) *string { return &val }
-func boolPtr(val bool) *bool       { return &val }
+func intPtr(val int) *int           { return &val }
+func stringPtr(val string) *string  { return &val }
+func boolPtr(val bool) *bool        { return &val }
+func floatPtr(val float64) *float64 { return &val }
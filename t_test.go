@@ -1,8 +1,15 @@
 package oas
 
 import (
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
 	"net/http"
 	r "reflect"
+	"strings"
 	"testing"
 	"time"
 	u "unsafe"
@@ -443,6 +450,1678 @@ compatible with the OpenAPI specification.
 	return doc
 }
 
+func TestMarshalYaml(t *testing.T) {
+	test := func(exp string, val interface{}) {
+		t.Helper()
+		out, err := MarshalYaml(val)
+		try(err)
+		eq(t, exp, string(out))
+	}
+
+	test("null\n", nil)
+	test("{}\n", Doc{})
+	test("[]\n", []string{})
+	test("\"yes\"\n", `yes`)
+	test("- - 1\n  - 2\n- - 3\n", [][]int{{1, 2}, {3}})
+
+	test(
+		`openapi: "3.1.0"
+info:
+  description: |
+
+    Documentation in JSON or YAML format,
+    compatible with the OpenAPI specification.
+  title: API documentation for my server
+  version: v3
+paths:
+  /ents:
+    post:
+      summary: /ents
+      responses:
+        "200":
+          description: |-
+            first
+            second
+        default:
+          description: "key: value"
+security:
+  - token:
+      - read
+      - write
+  - {}
+`,
+		Doc{
+			Openapi: Ver,
+			Info:    tDoc().Info,
+			Paths: Paths{}.Route(`/ents`, http.MethodPost, Op{
+				Resps: Resps{
+					`200`:     {Desc: "first\nsecond"},
+					`default`: {Desc: `key: value`},
+				},
+			}),
+			Security: []SecReq{{`token`: {`read`, `write`}}, {}},
+		},
+	)
+}
+
+func TestDecodeJson(t *testing.T) {
+	src := `{
+		"openapi": "3.1.0",
+		"info": {"title": "test", "version": "1", "x-logo": {"url": "logo.png"}},
+		"paths": {
+			"/users/{id}": {
+				"summary": "users",
+				"get": {
+					"tags": ["users"],
+					"operationId": "getUser",
+					"parameters": [{"$ref": "#/components/parameters/Id"}],
+					"responses": {
+						"200": {
+							"description": "ok",
+							"content": {
+								"application/json": {
+									"schema": {"$ref": "#/components/schemas/User"},
+									"example": {"id": 12345678901234567890}
+								}
+							}
+						},
+						"x-resps": "two"
+					},
+					"callbacks": {
+						"onEvent": {
+							"{$request.body#/url}": {"post": {"responses": {"204": {"description": "ok"}}}},
+							"x-cb": true
+						}
+					}
+				}
+			},
+			"x-paths": 1
+		},
+		"components": {
+			"schemas": {
+				"User": {
+					"type": "object",
+					"required": ["id"],
+					"properties": {"id": {"type": ["integer"], "maximum": 1.5}, "any": true, "none": false},
+					"enum": [1, "two", null],
+					"x-internal": true,
+					"unknownKeyword": 1
+				}
+			},
+			"parameters": {"Id": {"name": "id", "in": "path", "required": true, "schema": {"type": "string"}}},
+			"examples": {"One": {"value": {"id": 1}}}
+		}
+	}`
+
+	exp := Doc{
+		Openapi: `3.1.0`,
+		Info:    &Info{Title: `test`, Ver: `1`, Exts: Anys{`x-logo`: map[string]any{`url`: `logo.png`}}},
+		Paths: Paths{`/users/{id}`: {
+			Sum: `users`,
+			Get: &Op{
+				Tags:   []string{`users`},
+				OpId:   `getUser`,
+				Params: []Param{{Head: Head{Ref: `#/components/parameters/Id`}}},
+				Resps: Resps{`200`: {
+					Desc: `ok`,
+					Cont: MediaTypes{ConTypeJson: {
+						Schema:  RefSchema(`User`),
+						Example: map[string]any{`id`: json.Number(`12345678901234567890`)},
+					}},
+				}},
+				RespsExts: Anys{`x-resps`: `two`},
+				Callbacks: Callbacks{`onEvent`: {
+					Paths: Paths{`{$request.body#/url}`: {Post: &Op{Resps: Resps{`204`: {Desc: `ok`}}}}},
+					Exts:  Anys{`x-cb`: true},
+				}},
+			},
+		}},
+		PathsExts: Anys{`x-paths`: json.Number(`1`)},
+		Comps: Comps{
+			Schemas: Schemas{`User`: {
+				Type: []string{TypeObj},
+				Requ: []string{`id`},
+				Props: Schemas{
+					`id`:   {Type: []string{TypeInt}, Max: floatPtr(1.5)},
+					`any`:  BoolSchema(true),
+					`none`: BoolSchema(false),
+				},
+				Enum: []any{json.Number(`1`), `two`, nil},
+				Exts: Anys{`x-internal`: true, `unknownKeyword`: json.Number(`1`)},
+			}},
+			Params: Params{`Id`: {
+				Name: `id`,
+				In:   InPath,
+				Head: Head{Requ: true, Schema: &Schema{Type: []string{TypeStr}}},
+			}},
+			Examples: Examples{`One`: {Val: map[string]any{`id`: json.Number(`1`)}}},
+		},
+	}
+
+	var doc Doc
+	try(DecodeJson([]byte(src), &doc))
+	eq(t, exp, doc)
+
+	var again Doc
+	try(json.Unmarshal([]byte(jsonStr(doc)), &again))
+	eq(t, exp, again)
+
+	out, err := json.Marshal(doc.Comps.Schemas[`User`].Props[`none`])
+	try(err)
+	eq(t, `false`, string(out))
+
+	out, err = json.Marshal(Doc{PathsExts: Anys{`x-one`: 1}})
+	try(err)
+	eq(t, `{"paths":{"x-one":1}}`, string(out))
+
+	out, err = MarshalYaml(doc)
+	try(err)
+	eq(t, true, strings.Contains(string(out), "\n        x-resps: two\n"))
+	eq(t, true, strings.Contains(string(out), "\n  x-paths: 1\n"))
+}
+
+func TestDecodeJson_v30(t *testing.T) {
+	var doc Doc
+	try(DecodeJson([]byte(`{
+		"openapi": "3.0.3",
+		"components": {"schemas": {
+			"One": {"type": "string", "nullable": true},
+			"Two": {"type": "integer", "maximum": 10, "exclusiveMaximum": true},
+			"Three": {"type": "string", "enum": ["one"], "nullable": true},
+			"Four": {"enum": ["one"], "nullable": true}
+		}}
+	}`), &doc))
+
+	eq(
+		t,
+		Schemas{
+			`One`:   {Type: []string{TypeStr, TypeNull}},
+			`Two`:   {Type: []string{TypeInt}, ExlcMax: floatPtr(10)},
+			`Three`: {Type: []string{TypeStr, TypeNull}, Enum: []any{`one`, nil}},
+			`Four`:  {Enum: []any{`one`}},
+		},
+		doc.Comps.Schemas,
+	)
+}
+
+func TestDecodeJson_roundtrip(t *testing.T) {
+	src := `{"openapi":"3.1.0","components":{` +
+		`"schemas":{"One":{"const":null,"default":null}},` +
+		`"parameters":{"One":{"style":"form","explode":false,"name":"one","in":"query"}},` +
+		`"securitySchemes":{"One":{"type":"oauth2","flows":{"clientCredentials":{"tokenUrl":"/token","scopes":{}}}}}` +
+		`}}`
+
+	var doc Doc
+	try(DecodeJson([]byte(src), &doc))
+	eq(t, boolPtr(false), doc.Comps.Params[`One`].Explode)
+	eq(t, any(Null{}), doc.Comps.Schemas[`One`].Default)
+
+	out, err := json.Marshal(doc)
+	try(err)
+	eq(t, src, string(out))
+
+	out, err = json.Marshal(Flow{TokenUrl: `/token`})
+	try(err)
+	eq(t, `{"tokenUrl":"/token","scopes":{}}`, string(out))
+
+	out, err = MarshalYaml(doc.Comps.Schemas[`One`])
+	try(err)
+	eq(t, "const: null\ndefault: null\n", string(out))
+
+	try(doc.Validate(doc.Comps.Schemas[`One`], nil))
+	eq(t, true, doc.Validate(doc.Comps.Schemas[`One`], 1) != nil)
+}
+
+func TestDecodeJson_error(t *testing.T) {
+	test := func(ptr, src string) {
+		t.Helper()
+		var doc Doc
+		err := DecodeJson([]byte(src), &doc)
+
+		var decErr DecodeErr
+		if !errors.As(err, &decErr) {
+			t.Fatalf(`expected DecodeErr, got %#v`, err)
+		}
+		eq(t, ptr, decErr.Ptr)
+	}
+
+	test(`/paths/~1users/get/responses`, `{"paths": {"/users": {"get": {"responses": []}}}}`)
+	test(`/components/schemas/One/properties/a~0b/minLength`, `{"components": {"schemas": {"One": {"properties": {"a~b": {"minLength": "1"}}}}}}`)
+	test(`/info/unknown`, `{"info": {"unknown": 1}}`)
+	test(`/components/schemas/One/exclusiveMaximum`, `{"openapi": "3.1.0", "components": {"schemas": {"One": {"maximum": 1, "exclusiveMaximum": true}}}}`)
+	test(``, `[]`)
+}
+
+func TestDoc_Merge(t *testing.T) {
+	one := func() Doc {
+		return Doc{
+			Openapi: `3.1.0`,
+			Info:    &Info{Title: `one`},
+			Paths: Paths{
+				`/users`: {Get: &Op{
+					Tags:  []string{`users`},
+					Resps: Resps{`200`: {Desc: `ok`}},
+				}},
+			},
+			Comps: Comps{
+				Schemas: Schemas{
+					`User`: {Type: []string{TypeObj}},
+					`Same`: {Type: []string{TypeStr}},
+				},
+				SecSchemes: SecSchemes{`auth`: {Type: `http`, Scheme: `basic`}},
+			},
+			Security: []SecReq{{`auth`: nil}},
+			Tags:     []Tag{{Name: `users`, Desc: `one`}},
+		}
+	}
+
+	two := func() Doc {
+		return Doc{
+			Info: &Info{Title: `two`},
+			Paths: Paths{
+				`/users`: {Post: &Op{
+					Tags: []string{`users`},
+					ReqBody: &Body{Cont: MediaTypes{
+						ConTypeJson: {Schema: RefSchema(`User`)},
+					}},
+					Sec: []SecReq{{`auth`: nil}},
+				}},
+			},
+			Comps: Comps{
+				Schemas: Schemas{
+					`User`: {Type: []string{TypeArr}},
+					`Same`: {Type: []string{TypeStr}},
+				},
+				SecSchemes: SecSchemes{`auth`: {Type: `http`, Scheme: `bearer`}},
+			},
+			Tags: []Tag{{Name: `users`, Desc: `two`}},
+		}
+	}
+
+	t.Run(`strict`, func(t *testing.T) {
+		doc := one()
+		err := doc.Merge(two(), MergeOpt{})
+
+		var ptrs []string
+		for _, err := range err.(interface{ Unwrap() []error }).Unwrap() {
+			var mergeErr MergeErr
+			if !errors.As(err, &mergeErr) || !errors.Is(err, ErrMergeConflict) {
+				t.Fatalf(`expected MergeErr, got %#v`, err)
+			}
+			ptrs = append(ptrs, mergeErr.Ptr)
+		}
+
+		eq(t, []string{
+			`/components/schemas/User`,
+			`/components/securitySchemes/auth`,
+			`/tags/0`,
+		}, ptrs)
+		eq(t, one(), doc)
+	})
+
+	t.Run(`left`, func(t *testing.T) {
+		src := two()
+		doc, err := MergeDocs(MergeOpt{Mode: MergeLeft}, one(), src)
+		try(err)
+
+		eq(t, two(), src)
+		eq(t, `one`, doc.Info.Title)
+		eq(t, &Op{
+			Tags:  []string{`users`},
+			Resps: Resps{`200`: {Desc: `ok`}},
+			Sec:   []SecReq{{`auth`: nil}},
+		}, doc.Paths[`/users`].Get)
+		eq(t, two().Paths[`/users`].Post, doc.Paths[`/users`].Post)
+		eq(t, one().Comps.Schemas, doc.Comps.Schemas)
+		eq(t, one().Tags, doc.Tags)
+	})
+
+	t.Run(`rename`, func(t *testing.T) {
+		doc := one()
+		try(doc.Merge(two(), MergeOpt{Mode: MergeRename, Prefix: `Two`}))
+
+		eq(t, Schemas{
+			`User`:    {Type: []string{TypeObj}},
+			`TwoUser`: {Type: []string{TypeArr}},
+			`Same`:    {Type: []string{TypeStr}},
+		}, doc.Comps.Schemas)
+
+		post := doc.Paths[`/users`].Post
+		eq(t, `#/components/schemas/TwoUser`, post.ReqBody.Cont[ConTypeJson].Schema.Ref)
+		eq(t, []SecReq{{`Twoauth`: nil}}, post.Sec)
+		eq(t, []string{`Twousers`}, post.Tags)
+		eq(t, []Tag{{Name: `users`, Desc: `one`}, {Name: `Twousers`, Desc: `two`}}, doc.Tags)
+		eq(t, []SecReq(nil), doc.Security)
+		eq(t, []SecReq{{`auth`: nil}}, doc.Paths[`/users`].Get.Sec)
+	})
+
+	t.Run(`security`, func(t *testing.T) {
+		pub := Doc{Paths: Paths{`/public`: {Get: &Op{}}}}
+
+		doc, err := MergeDocs(MergeOpt{}, one())
+		try(err)
+		eq(t, []SecReq{{`auth`: nil}}, doc.Security)
+		eq(t, []SecReq(nil), doc.Paths[`/users`].Get.Sec)
+
+		doc, err = MergeDocs(MergeOpt{}, one(), pub)
+		try(err)
+		eq(t, []SecReq(nil), doc.Security)
+		eq(t, []SecReq{{`auth`: nil}}, doc.Paths[`/users`].Get.Sec)
+		eq(t, []SecReq(nil), doc.Paths[`/public`].Get.Sec)
+
+		doc, err = MergeDocs(MergeOpt{}, one(), Doc{Security: []SecReq{{`other`: nil}}})
+		try(err)
+		eq(t, []SecReq{{`auth`: nil}}, doc.Security)
+	})
+
+	t.Run(`path_params`, func(t *testing.T) {
+		src := two()
+		src.Paths = Paths{`/users/{key}`: {Get: &Op{}}}
+
+		doc := one()
+		doc.Paths[`/users/{id}`] = Path{Put: &Op{}}
+
+		var mergeErr MergeErr
+		if !errors.As(doc.Merge(src, MergeOpt{Mode: MergeRename, Prefix: `Two`}), &mergeErr) {
+			t.Fatal(`expected MergeErr`)
+		}
+		eq(t, `/paths/~1users~1{key}`, mergeErr.Ptr)
+
+		try(doc.Merge(src, MergeOpt{Mode: MergeLeft}))
+		eq(t, []string{`/users`, `/users/{id}`}, sortedKeys(doc.Paths))
+	})
+
+	t.Run(`path_conflict`, func(t *testing.T) {
+		src := two()
+		src.Paths[`/users`] = one().Paths[`/users`]
+		src.Paths[`/users`].Get.Desc = `other`
+
+		var mergeErr MergeErr
+		doc := one()
+		if !errors.As(doc.Merge(src, MergeOpt{Mode: MergeRename, Prefix: `Two`}), &mergeErr) {
+			t.Fatal(`expected MergeErr`)
+		}
+		eq(t, `/paths/~1users/get`, mergeErr.Ptr)
+	})
+}
+
+func TestDoc_Filter(t *testing.T) {
+	src := Doc{
+		Paths: Paths{
+			`/users`: {
+				Get: &Op{
+					Tags:  []string{`users`},
+					Resps: Resps{`200`: {Cont: MediaTypes{ConTypeJson: {Schema: RefSchema(`User`)}}}},
+				},
+				Post: &Op{
+					Tags:  []string{`admin`},
+					Resps: Resps{`200`: {Cont: MediaTypes{ConTypeJson: {Schema: RefSchema(`Secret`)}}}},
+					Sec:   []SecReq{{`admin~auth`: nil}},
+					Exts:  Anys{`x-internal`: true},
+				},
+			},
+			`/admin`: {Get: &Op{Tags: []string{`admin`}}},
+		},
+		Comps: Comps{
+			Schemas: Schemas{
+				`User`:   {Type: []string{TypeObj}},
+				`Secret`: {Props: Schemas{`user`: RefSchema(`User`), `key`: RefSchema(`Key`)}},
+				`Key`:    {Type: []string{TypeStr}},
+				`Unused`: {Type: []string{TypeStr}},
+			},
+			SecSchemes: SecSchemes{`admin~auth`: {Type: `http`}},
+		},
+		Tags: []Tag{{Name: `users`}, {Name: `admin`}, {Name: `unused`}},
+	}
+	prev := src.Clone()
+
+	doc := src.Filter(FilterAnd(
+		FilterNot(FilterExt(`x-internal`, true)),
+		FilterNot(FilterPrefix(`/admin`)),
+	))
+
+	eq(t, prev, src)
+	eq(t, Paths{`/users`: {Get: src.Paths[`/users`].Get}}, doc.Paths)
+	eq(t, Schemas{`User`: src.Comps.Schemas[`User`], `Unused`: src.Comps.Schemas[`Unused`]}, doc.Comps.Schemas)
+	eq(t, SecSchemes{}, doc.Comps.SecSchemes)
+	eq(t, []Tag{{Name: `users`}, {Name: `unused`}}, doc.Tags)
+
+	doc = src.Filter(FilterTags(`admin`))
+	eq(t, 2, len(doc.Paths))
+	eq(t, (*Op)(nil), doc.Paths[`/users`].Get)
+	eq(t, src.Comps.Schemas, doc.Comps.Schemas)
+	eq(t, []Tag{{Name: `admin`}, {Name: `unused`}}, doc.Tags)
+
+	src.Paths[`/shared`] = Path{Ref: `#/components/pathItems/Shared`}
+	src.Paths[`/params`] = Path{Params: []Param{{Name: `id`, In: InQuery}}}
+	src.Comps.Paths = Paths{`Shared`: {Get: &Op{Tags: []string{`shared`}}}}
+
+	doc = src.Filter(FilterTags(`shared`))
+	eq(t, []string{`/params`, `/shared`}, sortedKeys(doc.Paths))
+	eq(t, src.Paths[`/shared`], doc.Paths[`/shared`])
+	eq(t, src.Comps.Paths, doc.Comps.Paths)
+
+	doc = src.Filter(FilterTags(`users`))
+	eq(t, []string{`/params`, `/users`}, sortedKeys(doc.Paths))
+	eq(t, Paths{}, doc.Comps.Paths)
+}
+
+func TestDoc_Prune(t *testing.T) {
+	var doc Doc
+	doc.Route(`/users`, http.MethodGet, Op{
+		Resps: Resps{`200`: {Ref: `#/components/responses/Users`}},
+	})
+	doc.Comps.Resps = Resps{`Users`: {Cont: MediaTypes{ConTypeJson: doc.SchemaMedia([]Unit(nil))}}}
+	doc.Comps.Params = Params{`Unused`: {Head: Head{Desc: `unused`}}}
+	doc.Comps.Heads = Heads{`Unused`: {Desc: `unused`}}
+	doc.Comps.SecSchemes = SecSchemes{`Unused`: {Type: `http`}}
+	doc.Sch(Outer{})
+	doc.Comps.Schemas[`Recursive`] = Schema{Items: RefSchema(`Recursive`).Opt()}
+
+	eq(t, map[string]bool{
+		`#/components/responses/Users`:    true,
+		`#/components/schemas/[]oas.Unit`: true,
+		`#/components/schemas/oas.Unit`:   true,
+	}, doc.Reachable())
+
+	eq(t, []string{
+		`#/components/headers/Unused`,
+		`#/components/parameters/Unused`,
+		`#/components/schemas/Recursive`,
+		`#/components/schemas/[]oas.Pair`,
+		`#/components/schemas/oas.Inner`,
+		`#/components/schemas/oas.Outer`,
+		`#/components/schemas/oas.Pair`,
+	}, doc.Prune())
+
+	eq(t, []string{`Users`}, sortedKeys(doc.Comps.Resps))
+	eq(t, []string{`Unused`}, sortedKeys(doc.Comps.SecSchemes))
+	eq(t, []string(nil), doc.Prune())
+}
+
+func TestWalk(t *testing.T) {
+	doc := Doc{
+		Info: &Info{Title: `one`},
+		Paths: Paths{
+			`/users/{id}`: {
+				Params: []Param{{Head: Head{Desc: `id`}, Name: `id`}},
+				Get: &Op{
+					Resps: Resps{`200`: {Cont: MediaTypes{ConTypeJson: {
+						Schema: Schema{
+							Props:   Schemas{`one`: {Title: `one`}},
+							AllOf:   []Schema{{Title: `two`}},
+							Items:   &Schema{Title: `three`},
+							Example: Schema{Title: `ignored`},
+						},
+					}}}},
+					Callbacks: Callbacks{`cb`: {Paths: Paths{`/hook`: {Post: &Op{
+						Resps: Resps{`204`: {Cont: MediaTypes{`text/plain`: {}}}},
+					}}}}},
+				},
+			},
+		},
+		Exts: Anys{`x-ignored`: Schema{}},
+	}
+
+	var ptrs []string
+	try(doc.Walk(func(ptr string, val any) error {
+		ptrs = append(ptrs, fmt.Sprintf(`%v %T`, ptr, val))
+
+		sch, _ := val.(*Schema)
+		if sch != nil {
+			sch.Desc = `visited`
+		}
+		return nil
+	}))
+
+	eq(t, []string{
+		` *oas.Doc`,
+		`/info *oas.Info`,
+		`/paths/~1users~1{id} *oas.Path`,
+		`/paths/~1users~1{id}/get *oas.Op`,
+		`/paths/~1users~1{id}/get/responses/200 *oas.Resp`,
+		`/paths/~1users~1{id}/get/responses/200/content/application~1json *oas.MediaType`,
+		`/paths/~1users~1{id}/get/responses/200/content/application~1json/schema *oas.Schema`,
+		`/paths/~1users~1{id}/get/responses/200/content/application~1json/schema/allOf/0 *oas.Schema`,
+		`/paths/~1users~1{id}/get/responses/200/content/application~1json/schema/items *oas.Schema`,
+		`/paths/~1users~1{id}/get/responses/200/content/application~1json/schema/properties/one *oas.Schema`,
+		`/paths/~1users~1{id}/get/callbacks/cb *oas.Callback`,
+		`/paths/~1users~1{id}/get/callbacks/cb/~1hook *oas.Path`,
+		`/paths/~1users~1{id}/get/callbacks/cb/~1hook/post *oas.Op`,
+		`/paths/~1users~1{id}/get/callbacks/cb/~1hook/post/responses/204 *oas.Resp`,
+		`/paths/~1users~1{id}/get/callbacks/cb/~1hook/post/responses/204/content/text~1plain *oas.MediaType`,
+		`/paths/~1users~1{id}/parameters/0 *oas.Param`,
+	}, ptrs)
+
+	sch := doc.Paths[`/users/{id}`].Get.Resps[`200`].Cont[ConTypeJson].Schema
+	eq(t, `visited`, sch.Desc)
+	eq(t, `visited`, sch.Props[`one`].Desc)
+	eq(t, `visited`, sch.Items.Desc)
+	eq(t, ``, sch.Example.(Schema).Desc)
+
+	ptrs = nil
+	try(doc.Walk(func(ptr string, val any) error {
+		ptrs = append(ptrs, ptr)
+		if ptr == `/info` {
+			return WalkStop
+		}
+		_, ok := val.(*Path)
+		if ok {
+			return WalkSkip
+		}
+		return nil
+	}))
+	eq(t, []string{``, `/info`}, ptrs)
+
+	ptrs = nil
+	try(doc.Walk(func(ptr string, val any) error {
+		ptrs = append(ptrs, ptr)
+		_, ok := val.(*Path)
+		if ok {
+			return WalkSkip
+		}
+		return nil
+	}))
+	eq(t, []string{``, `/info`, `/paths/~1users~1{id}`}, ptrs)
+
+	err := errors.New(`fail`)
+	eq(t, err, doc.Walk(func(string, any) error { return err }))
+}
+
+func TestDoc_Resolve(t *testing.T) {
+	doc := Doc{
+		Paths: Paths{
+			`/users/{id}`: {Get: &Op{
+				OpId:  `getUser`,
+				Resps: Resps{`404`: {Ref: `#/components/responses/Missing`}},
+			}},
+		},
+		Comps: Comps{
+			Resps: Resps{
+				`Missing`:  {Ref: `#/components/responses/NotFound`},
+				`NotFound`: {Desc: `not found`},
+			},
+			Schemas: Schemas{
+				`One`:   {Props: Schemas{`a/b~c`: {Title: `prop`}}},
+				`Alias`: RefSchema(`One`),
+				`Loop0`: RefSchema(`Loop1`),
+				`Loop1`: RefSchema(`Loop0`),
+			},
+			Examples: Examples{`Ex`: {Val: map[string]any{`list`: []any{10, 20}}}},
+		},
+		Exts: Anys{`x-one`: `two`},
+	}
+
+	test := func(exp any, ref string) {
+		t.Helper()
+		val, err := doc.Resolve(ref)
+		try(err)
+		eq(t, exp, val)
+	}
+
+	test(Resp{Desc: `not found`}, `#/components/responses/Missing`)
+	test(Resp{Desc: `not found`}, `#/paths/~1users~1{id}/get/responses/404`)
+	test(Resp{Desc: `not found`}, `#/paths/~1users~1%7Bid%7D/get/responses/404`)
+	test(`getUser`, `#/paths/~1users~1{id}/get/operationId`)
+	test(Schema{Title: `prop`}, `#/components/schemas/One/properties/a~1b~0c`)
+	test(doc.Comps.Schemas[`One`], `#/components/schemas/Alias`)
+	test(20, `#/components/examples/Ex/value/list/1`)
+	test(`two`, `#/x-one`)
+	test(doc, `#`)
+
+	val, err := doc.Pointer(`/components/schemas/Alias`)
+	try(err)
+	eq(t, RefSchema(`One`), val)
+
+	op, err := ResolveAs[Op](&doc, `#/paths/~1users~1{id}/get`)
+	try(err)
+	eq(t, `getUser`, op.OpId)
+
+	_, err = ResolveAs[Schema](&doc, `#/paths/~1users~1{id}/get`)
+	eq(t, `[oas] reference "#/paths/~1users~1{id}/get" resolved to oas.Op, expected oas.Schema`, err.Error())
+
+	fail := func(msg, ref string) {
+		t.Helper()
+		_, err := doc.Resolve(ref)
+		if err == nil || !strings.Contains(err.Error(), msg) {
+			t.Fatalf(`expected error containing %q, got %v`, msg, err)
+		}
+	}
+
+	fail(`reference cycle`, `#/components/schemas/Loop0`)
+	fail(`nothing at "/components/schemas/Missing"`, `#/components/schemas/Missing`)
+	fail(`nothing at "/info"`, `#/info/title`)
+	fail(`nothing at "/components/examples/Ex/value/list/01"`, `#/components/examples/Ex/value/list/01`)
+	fail(`only local references`, `other.json#/components/schemas/One`)
+	fail(`must be empty or start with "/"`, `#components`)
+
+	sch, ok := doc.GotSchema(`#/components/schemas/Alias`)
+	eq(t, true, ok)
+	eq(t, doc.Comps.Schemas[`One`], sch)
+
+	_, ok = doc.GotSchema(`#/components/responses/NotFound`)
+	eq(t, false, ok)
+}
+
+func TestLint(t *testing.T) {
+	var doc Doc
+	doc.Info = &Info{Title: `test`}
+	doc.Route(`/users/{id}`, http.MethodGet, Op{
+		OpId:   `getUser`,
+		Desc:   `Returns one user.`,
+		Params: []Param{{Name: `id`, In: InPath}, {Name: `extra`, In: InPath}},
+		Resps:  Resps{`200`: {Cont: MediaTypes{ConTypeJson: doc.SchemaMedia(Unit{})}}},
+	})
+	doc.Route(`/userGroups/{id}`, http.MethodPost, Op{
+		OpId:  `getUser`,
+		Resps: Resps{`404`: {Desc: `not found`}},
+	})
+	doc.Comps.Heads = Heads{`Unused`: {}}
+
+	var rules []string
+	for _, diag := range Lint(&doc, LintOpt{Skip: []string{`op-id-missing`}}) {
+		rules = append(rules, diag.String())
+	}
+
+	eq(t, []string{
+		`warning at "/paths/~1userGroups~1{id}/post": operation has no description (op-desc)`,
+		`error at "/paths/~1users~1{id}/get/operationId": operationId "getUser" is already used at "/paths/~1userGroups~1{id}/post" (op-id-duplicate)`,
+		`warning at "/paths/~1userGroups~1{id}/post/responses": operation has no 2XX response (op-success)`,
+		`error at "/paths/~1userGroups~1{id}/post": path parameter "id" is not declared (path-params)`,
+		`error at "/paths/~1users~1{id}/get": path parameter "extra" is not in the path template (path-params)`,
+		`warning at "/components/headers/Unused": component is not referenced (unused-comps)`,
+		`info at "/paths/~1userGroups~1{id}": path segment "userGroups" is not kebab-case (path-kebab)`,
+		`error at "/info": missing info.version (info-version)`,
+		`info at "/components/schemas/oas.Unit": component name "oas.Unit" looks like a Go type name (schema-go-name)`,
+		`info at "/components/schemas/oas.Unit/title": schema title "oas.Unit" looks like a Go type name (schema-go-name)`,
+	}, rules)
+
+	diags := Lint(&doc, LintOpt{
+		Skip: []string{`op-desc`, `op-id-duplicate`, `op-success`, `path-params`, `unused-comps`, `path-kebab`, `schema-go-name`},
+		Sev:  map[string]Sev{`info-version`: SevWarn},
+		Rules: []LintRule{{
+			Name: `no-post`,
+			Sev:  SevError,
+			Check: func(doc *Doc, report func(string, string)) {
+				for key, path := range doc.Paths {
+					if path.Post != nil {
+						report(ptrAppend(`/paths`, key), `POST is forbidden`)
+					}
+				}
+			},
+		}},
+	})
+
+	eq(t, Diags{
+		{Rule: `info-version`, Sev: SevWarn, Ptr: `/info`, Msg: `missing info.version`},
+		{Rule: `no-post`, Sev: SevError, Ptr: `/paths/~1userGroups~1{id}`, Msg: `POST is forbidden`},
+	}, diags)
+
+	eq(t, nil, Diags(nil).Err(SevInfo))
+	eq(t, `[oas] lint found 1 problems:
+	error at "/paths/~1userGroups~1{id}": POST is forbidden (no-post)`, diags.Err(SevError).Error())
+}
+
+func TestDiff(t *testing.T) {
+	user := func(props Schemas, requ ...string) Schema {
+		return Schema{Type: []string{TypeObj}, Props: props, Requ: requ}
+	}
+	media := func(sch Schema) MediaTypes { return MediaTypes{ConTypeJson: {Schema: sch}} }
+
+	var prev Doc
+	prev.Comps.Schemas = Schemas{
+		`User`: user(Schemas{
+			`id`:   {Type: []string{TypeInt}},
+			`name`: {Type: []string{TypeStr}},
+			`role`: {Type: []string{TypeStr}, Enum: []any{`admin`, `user`}},
+		}, `id`),
+	}
+	prev.Route(`/users`, http.MethodGet, Op{
+		Params: []Param{{Name: `limit`, In: InQuery}},
+		Resps:  Resps{`200`: {Cont: media(RefSchema(`User`))}},
+	})
+	prev.Route(`/users`, http.MethodPost, Op{
+		ReqBody: &Body{Cont: media(RefSchema(`User`))},
+		Resps:   Resps{`201`: {}},
+	})
+	prev.Route(`/users/{id}`, http.MethodDelete, Op{})
+
+	// Same content, renamed.
+	same := prev.Clone()
+	same.Comps.Schemas = Schemas{`Person`: prev.Comps.Schemas[`User`]}
+	refsRewrite(&same, func(string) string { return `#/components/schemas/Person` })
+	eq(t, Changes(nil), Diff(&prev, &same))
+
+	var next Doc
+	next.Comps.Schemas = Schemas{
+		`User`: user(Schemas{
+			`id`:    {Type: []string{TypeStr}},
+			`role`:  {Type: []string{TypeStr}, Enum: []any{`admin`}},
+			`email`: {Type: []string{TypeStr}},
+		}, `id`, `email`),
+	}
+	next.Route(`/users`, http.MethodGet, Op{
+		Params: []Param{{Name: `limit`, In: InQuery, Head: Head{Requ: true}}},
+		Resps:  Resps{`200`: {Cont: media(RefSchema(`User`))}, `400`: {}},
+	})
+	next.Route(`/users`, http.MethodPost, Op{
+		ReqBody: &Body{Cont: media(RefSchema(`User`))},
+		Resps:   Resps{`201`: {}},
+	})
+	next.Route(`/users/{id}`, http.MethodPut, Op{})
+
+	changes := Diff(&prev, &next)
+	eq(t, `breaking at "/paths/~1users/get/parameters/0": parameter "limit" in query became required (param-required)
+non-breaking at "/paths/~1users/get/responses/200/content/application~1json/schema/properties/email": property "email" became required (required-added)
+non-breaking at "/paths/~1users/get/responses/200/content/application~1json/schema/properties/email": added property "email" (prop-added)
+breaking at "/paths/~1users/get/responses/200/content/application~1json/schema/properties/id/type": type changed from ["integer"] to ["string"] (type-changed)
+breaking at "/paths/~1users/get/responses/200/content/application~1json/schema/properties/name": removed property "name" (prop-removed)
+non-breaking at "/paths/~1users/get/responses/200/content/application~1json/schema/properties/role/enum": enum narrowed, removed values: [user] (enum-narrowed)
+non-breaking at "/paths/~1users/get/responses/400": added response "400" (resp-added)
+breaking at "/paths/~1users/post/requestBody/content/application~1json/schema/properties/email": property "email" became required (required-added)
+non-breaking at "/paths/~1users/post/requestBody/content/application~1json/schema/properties/email": added property "email" (prop-added)
+breaking at "/paths/~1users/post/requestBody/content/application~1json/schema/properties/id/type": type changed from ["integer"] to ["string"] (type-changed)
+non-breaking at "/paths/~1users/post/requestBody/content/application~1json/schema/properties/name": removed property "name" (prop-removed)
+breaking at "/paths/~1users/post/requestBody/content/application~1json/schema/properties/role/enum": enum narrowed, removed values: [user] (enum-narrowed)
+non-breaking at "/paths/~1users~1{id}/put": added operation PUT /users/{id} (op-added)
+breaking at "/paths/~1users~1{id}/delete": removed operation DELETE /users/{id} (op-removed)
+`, changes.String())
+	eq(t, 7, len(changes.Breaking()))
+
+	// Shared schemas are reported at every use site.
+	prev.Route(`/admins`, http.MethodGet, Op{Resps: Resps{`200`: {Cont: media(RefSchema(`User`))}}})
+	next.Route(`/admins`, http.MethodGet, Op{Resps: Resps{`200`: {Cont: media(RefSchema(`User`))}}})
+	changes = Diff(&prev, &next)
+	eq(t, 19, len(changes))
+	eq(t, `/paths/~1admins/get/responses/200/content/application~1json/schema/properties/name`, changes[3].Ptr)
+	eq(t, `/paths/~1users/get/responses/200/content/application~1json/schema/properties/name`, changes[9].Ptr)
+}
+
+func TestDiff_headers(t *testing.T) {
+	str := Schema{Type: []string{TypeStr}}
+	arr := Schema{Type: []string{TypeArr}}
+
+	var prev Doc
+	prev.Route(`/`, http.MethodPost, Op{
+		ReqBody: &Body{Cont: MediaTypes{ConTypeJson: {Schema: arr}}},
+		Resps: Resps{`200`: {
+			Head: Heads{
+				`X-Id`:         {Requ: true, Schema: &str},
+				`X-Rate`:       {Requ: true},
+				`X-Old`:        {},
+				`Content-Type`: {},
+			},
+			Cont: MediaTypes{ConTypeJson: {Schema: Schema{Type: []string{TypeArr}, Items: &str}}},
+		}},
+	})
+
+	var next Doc
+	next.Route(`/`, http.MethodPost, Op{
+		ReqBody: &Body{Cont: MediaTypes{ConTypeJson: {Schema: Schema{Type: []string{TypeArr}, Items: &str}}}},
+		Resps: Resps{`200`: {
+			Head: Heads{
+				`X-Id`:   {Requ: true, Schema: &Schema{Type: []string{TypeInt}}},
+				`X-Rate`: {},
+				`X-New`:  {},
+			},
+			Cont: MediaTypes{ConTypeJson: {Schema: arr}},
+		}},
+	})
+
+	eq(t, `breaking at "/paths/~1/post/requestBody/content/application~1json/schema/items": added items schema (items-added)
+breaking at "/paths/~1/post/responses/200/headers/X-Id/schema/type": type changed from ["string"] to ["integer"] (type-changed)
+non-breaking at "/paths/~1/post/responses/200/headers/X-New": added response header "X-New" (header-added)
+breaking at "/paths/~1/post/responses/200/headers/X-Old": removed response header "X-Old" (header-removed)
+breaking at "/paths/~1/post/responses/200/headers/X-Rate": response header "X-Rate" became optional (header-optional)
+breaking at "/paths/~1/post/responses/200/content/application~1json/schema/items": removed items schema (items-removed)
+`, Diff(&prev, &next).String())
+}
+
+func TestDiff_compositions(t *testing.T) {
+	test := func(kw string, prev, next []Schema, exp ...string) {
+		t.Helper()
+		doc := func(list []Schema) *Doc {
+			var sch Schema
+			r.ValueOf(&sch).Elem().FieldByName(kw).Set(r.ValueOf(list))
+			var doc Doc
+			doc.Comps.Schemas = Schemas{`One`: {Type: []string{TypeStr}}, `Two`: {Type: []string{TypeInt}}}
+			doc.Route(`/`, http.MethodPost, Op{
+				ReqBody: &Body{Cont: MediaTypes{ConTypeJson: {Schema: sch}}},
+				Resps:   Resps{`200`: {Cont: MediaTypes{ConTypeJson: {Schema: sch}}}},
+			})
+			return &doc
+		}
+
+		var act []string
+		for _, val := range Diff(doc(prev), doc(next)) {
+			act = append(act, val.String())
+		}
+		eq(t, exp, act)
+	}
+
+	one, two, three := RefSchema(`One`), RefSchema(`Two`), Schema{Type: []string{TypeBool}}
+
+	test(`AnyOf`, []Schema{one}, []Schema{two, one},
+		`non-breaking at "/paths/~1/post/requestBody/content/application~1json/schema/anyOf/0": added subschema to "anyOf" (subschema-added)`,
+		`breaking at "/paths/~1/post/responses/200/content/application~1json/schema/anyOf/0": added subschema to "anyOf" (subschema-added)`,
+	)
+
+	test(`OneOf`, []Schema{one, three}, []Schema{three},
+		`breaking at "/paths/~1/post/requestBody/content/application~1json/schema/oneOf/0": removed subschema from "oneOf" (subschema-removed)`,
+		`non-breaking at "/paths/~1/post/responses/200/content/application~1json/schema/oneOf/0": removed subschema from "oneOf" (subschema-removed)`,
+	)
+
+	test(`AllOf`, []Schema{one}, []Schema{one, two},
+		`breaking at "/paths/~1/post/requestBody/content/application~1json/schema/allOf/1": added subschema to "allOf" (subschema-added)`,
+		`non-breaking at "/paths/~1/post/responses/200/content/application~1json/schema/allOf/1": added subschema to "allOf" (subschema-added)`,
+	)
+
+	test(`AnyOf`, nil, []Schema{one},
+		`breaking at "/paths/~1/post/requestBody/content/application~1json/schema/anyOf/0": added subschema to "anyOf" (subschema-added)`,
+		`non-breaking at "/paths/~1/post/responses/200/content/application~1json/schema/anyOf/0": added subschema to "anyOf" (subschema-added)`,
+	)
+
+	test(`OneOf`, []Schema{one, two}, []Schema{two, one})
+}
+
+func TestDoc_Validate(t *testing.T) {
+	var doc Doc
+	doc.Comps.Schemas = Schemas{
+		`User`: {
+			Type: []string{TypeObj},
+			Props: Schemas{
+				`id`:    {Type: []string{TypeInt}, Format: FormatInt32, Min: floatPtr(1)},
+				`name`:  {Type: []string{TypeStr}, MinLen: 2, MaxLen: 4, Pattern: `^[a-z]+$`},
+				`email`: {Type: []string{TypeStr}, Format: FormatEmail},
+				`role`:  {Enum: []any{`admin`, `user`}},
+				`tags`:  {Type: []string{TypeArr}, Items: &Schema{Type: []string{TypeStr}}, UniqItems: true},
+				`score`: {Type: []string{TypeNum}, MulOf: 0.01, ExlcMax: floatPtr(100)},
+				`born`:  {Type: []string{TypeStr}, Format: FormatDate},
+			},
+			Requ:        []string{`id`, `name`},
+			UnevalProps: BoolSchema(false).Opt(),
+		},
+		`Admin`: {
+			AllOf: []Schema{RefSchema(`User`)},
+			Props: Schemas{`level`: {Const: 1}},
+		},
+	}
+
+	test := func(sch Schema, src string, exp ...string) {
+		t.Helper()
+		err := doc.ValidateJson(sch, []byte(src))
+
+		var act []string
+		for _, val := range validErrs(err) {
+			act = append(act, val.Error())
+		}
+		eq(t, exp, act)
+	}
+
+	test(RefSchema(`User`), `{"id": 1, "name": "abc", "email": "one@two.three", "role": "user", "tags": ["a", "b"], "score": 0.03, "born": "2020-01-02"}`)
+	test(RefSchema(`User`), `{"id": 1.0, "name": "ab"}`)
+
+	test(RefSchema(`User`), `{"id": 0, "name": "Abcde", "extra": true}`,
+		`at "/id" (schema "/$ref/properties/id/minimum"): 0 is less than 1`,
+		`at "/name" (schema "/$ref/properties/name/maxLength"): length 5 is greater than 4`,
+		`at "/name" (schema "/$ref/properties/name/pattern"): string doesn't match pattern "^[a-z]+$"`,
+		`at "/extra" (schema "/$ref/unevaluatedProperties"): boolean schema "false" rejects every instance`,
+	)
+
+	test(RefSchema(`User`), `{"id": 3000000000, "name": "ab", "email": "nope", "role": "root", "tags": ["a", "a", 1], "score": 100, "born": "2020-13-01"}`,
+		`at "/born" (schema "/$ref/properties/born/format"): string is not a valid "date"`,
+		`at "/email" (schema "/$ref/properties/email/format"): string is not a valid "email"`,
+		`at "/id" (schema "/$ref/properties/id/format"): 3000000000 is out of range for format "int32"`,
+		`at "/role" (schema "/$ref/properties/role/enum"): value is not one of the allowed values`,
+		`at "/score" (schema "/$ref/properties/score/exclusiveMaximum"): 100 is not less than 100`,
+		`at "/tags" (schema "/$ref/properties/tags/uniqueItems"): items at 0 and 1 are equal`,
+		`at "/tags/2" (schema "/$ref/properties/tags/items/type"): expected type string, got number`,
+	)
+
+	test(RefSchema(`User`), `[]`, `at "" (schema "/$ref/type"): expected type object, got array`)
+	test(RefSchema(`Missing`), `{}`, `at "" (schema "/$ref"): [oas] failed to resolve JSON Pointer: nothing at "/components/schemas/Missing"`)
+
+	// Properties evaluated by `allOf` are visible to `unevaluatedProperties`
+	// of the inner schema only.
+	test(RefSchema(`Admin`), `{"id": 1, "name": "ab", "level": 2}`,
+		`at "/level" (schema "/$ref/properties/level/const"): value doesn't match the constant`,
+		`at "/level" (schema "/$ref/allOf/0/$ref/unevaluatedProperties"): boolean schema "false" rejects every instance`,
+	)
+
+	test(Schema{Const: Null{}}, `null`)
+	test(Schema{Const: Null{}}, `0`, `at "" (schema "/const"): value doesn't match the constant`)
+
+	test(Schema{Type: []string{TypeNum}, Max: floatPtr(1)}, `1e1000`, `at "" (schema "/maximum"): 1e1000 is greater than 1`)
+	test(Schema{Type: []string{TypeNum}, Max: floatPtr(1)}, `1e1000000`, `at "" (schema ""): invalid or out-of-range number "1e1000000"`)
+	test(Schema{Type: []string{TypeInt}}, `1e-1000000`, `at "" (schema "/type"): expected type integer, got number`)
+
+	test(NullSchema(`nullable`, Schema{Type: []string{TypeStr}}), `null`)
+	test(NullSchema(`nullable`, Schema{Type: []string{TypeStr}}), `1`,
+		`at "" (schema "/oneOf"): value doesn't match any subschema`,
+		`at "" (schema "/oneOf/0/type"): expected type string, got number`,
+		`at "" (schema "/oneOf/1/type"): expected type null, got number`,
+	)
+
+	test(Schema{
+		PrefixItems: []Schema{{Type: []string{TypeStr}}},
+		Contains:    &Schema{Type: []string{TypeInt}},
+		MaxCont:     1,
+		UnevalItems: BoolSchema(false).Opt(),
+	}, `["a", 1, 2, true]`,
+		`at "" (schema "/maxContains"): array contains 2 matching items, expected at most 1`,
+		`at "/3" (schema "/unevaluatedItems"): boolean schema "false" rejects every instance`,
+	)
+
+	test(Schema{
+		If:   &Schema{Props: Schemas{`kind`: {Const: `a`}}, Requ: []string{`kind`}},
+		Then: &Schema{Requ: []string{`a`}},
+		Else: &Schema{Not: &Schema{Requ: []string{`a`}}},
+	}, `[{"kind": "a"}, {"kind": "b", "a": 1}]`)
+
+	var abs ValidErrs
+	errors.As(doc.Validate(RefSchema(`User`), map[string]any{`id`: -1, `name`: `ab`}), &abs)
+	eq(t, ValidErrs{{
+		Inst: `/id`,
+		Kw:   `/$ref/properties/id/minimum`,
+		Abs:  `#/components/schemas/User/properties/id/minimum`,
+		Msg:  `-1 is less than 1`,
+	}}, abs)
+
+	try(doc.Validate(RefSchema(`User`), struct {
+		Id   int    `json:"id"`
+		Name string `json:"name"`
+	}{1, `ab`}))
+}
+
+func validErrs(err error) ValidErrs {
+	if err == nil {
+		return nil
+	}
+	return err.(ValidErrs)
+}
+
+func TestDoc_Example(t *testing.T) {
+	type Node struct {
+		Id    string    `json:"id"`
+		Nodes []Node    `json:"nodes"`
+		Next  *Node     `json:"next"`
+		Inst  time.Time `json:"inst"`
+	}
+
+	var doc Doc
+	doc.Comps.Schemas = Schemas{
+		`User`: {
+			Type: []string{TypeObj},
+			Props: Schemas{
+				`id`:    {Type: []string{TypeInt}, Min: floatPtr(10), MulOf: 3},
+				`name`:  {Type: []string{TypeStr}, MinLen: 8},
+				`code`:  {Type: []string{TypeStr}, MaxLen: 3},
+				`email`: {Type: []string{TypeStr}, Format: FormatEmail},
+				`role`:  {Enum: []any{`admin`, `user`}},
+				`score`: {Type: []string{TypeNum, TypeNull}, ExclMin: floatPtr(0), ExlcMax: floatPtr(1)},
+				`tags`:  {Type: []string{TypeArr}, Items: &Schema{Type: []string{TypeStr}, Format: FormatUuid}, MinItems: 2},
+				`pass`:  {Type: []string{TypeStr}, Wonly: true},
+				`extra`: {AnyOf: []Schema{{Type: []string{TypeNull}}, {Type: []string{TypeBool}}}},
+			},
+			Requ: []string{`id`, `name`, `pass`},
+		},
+		`Admin`: {
+			AllOf: []Schema{RefSchema(`User`), {Props: Schemas{`level`: {Const: 1}}}},
+		},
+	}
+
+	eq(t, map[string]any{
+		`id`:    int64(12),
+		`name`:  `stringxx`,
+		`code`:  `str`,
+		`email`: `user@example.com`,
+		`role`:  `admin`,
+		`score`: 0.5,
+		`tags`:  []any{`3fa85f64-5717-4562-b3fc-2c963f66afa6`, `3fa85f64-5717-4562-b3fc-2c963f66afa6`},
+		`extra`: true,
+		`level`: 1,
+	}, doc.Example(RefSchema(`Admin`), ExampleOpt{}))
+
+	eq(t, map[string]any{`id`: int64(12), `name`: `stringxx`, `pass`: `string`}, doc.Example(RefSchema(`User`), ExampleOpt{RequOnly: true, Req: true}))
+
+	for _, name := range []string{`User`, `Admin`} {
+		try(doc.Validate(RefSchema(name), doc.Example(RefSchema(name), ExampleOpt{Req: true})))
+	}
+
+	eq(t, map[string]any{
+		`id`:    `string`,
+		`nodes`: []any{},
+		`next`:  nil,
+		`inst`:  `2024-01-02T15:04:05Z`,
+	}, doc.Example(doc.Sch(Node{}), ExampleOpt{}))
+
+	eq(t, nil, doc.Example(RefSchema(`Missing`), ExampleOpt{}))
+
+	doc.Comps.Schemas[`Tree`] = Schema{
+		Type: []string{TypeObj},
+		Props: Schemas{
+			`id`:     {Type: []string{TypeStr}},
+			`kids`:   {Type: []string{TypeArr}, Items: RefSchema(`Tree`).Opt()},
+			`root`:   RefSchema(`Tree`),
+			`parent`: {AnyOf: []Schema{RefSchema(`Tree`), {Type: []string{TypeNull}}}},
+			`next`:   RefSchema(`Tree`),
+		},
+		Requ: []string{`id`, `kids`, `root`, `parent`},
+	}
+	eq(t, map[string]any{
+		`id`:     `string`,
+		`kids`:   []any{},
+		`root`:   map[string]any{},
+		`parent`: nil,
+	}, doc.Example(RefSchema(`Tree`), ExampleOpt{}))
+
+	doc.Route(`/users`, http.MethodPost, Op{
+		ReqBody: doc.JsonBodyOpt(Node{}),
+		Resps: Resps{
+			`200`: {Cont: MediaTypes{ConTypeJson: {Schema: RefSchema(`User`)}}},
+			`201`: {Cont: MediaTypes{ConTypeJson: {Schema: RefSchema(`User`), Example: `custom`}}},
+			`400`: {Cont: MediaTypes{`text/plain`: {Schema: Schema{Type: []string{TypeStr}}}}},
+		},
+	})
+	doc.FillExamples(ExampleOpt{})
+
+	op := doc.Paths[`/users`].Post
+	eq(t, `string`, op.ReqBody.Cont[ConTypeJson].Example.(map[string]any)[`id`])
+	eq(t, false, hasKey(op.Resps[`200`].Cont[ConTypeJson].Example.(map[string]any), `pass`))
+	eq(t, `custom`, op.Resps[`201`].Cont[ConTypeJson].Example)
+	eq(t, nil, op.Resps[`400`].Cont[`text/plain`].Example)
+}
+
+func hasKey[A any](src map[string]A, key string) bool {
+	_, ok := src[key]
+	return ok
+}
+
+func TestDoc_MediaExample(t *testing.T) {
+	type Person struct {
+		Id   string  `json:"id"`
+		Name string  `json:"name"`
+		Age  float64 `json:"age,omitempty"`
+	}
+
+	var doc Doc
+	ann := Person{Id: `3a4e`, Name: `Ann`, Age: 30}
+
+	media := doc.ExampleMedia(ann)
+	eq(t, doc.Sch(Person{}), media.Schema)
+	eq(t, map[string]any{`id`: `3a4e`, `name`: `Ann`, `age`: json.Number(`30`)}, media.Example)
+
+	media = doc.SchemaMedia(Person{})
+	doc.MediaExample(&media, ``, ann)
+	doc.MediaExample(&media, `bob`, Person{Id: `5b6f`, Name: `Bob`})
+	eq(t, map[string]any{`id`: `3a4e`, `name`: `Ann`, `age`: json.Number(`30`)}, media.Example)
+	eq(t, Examples{`bob`: {Val: map[string]any{`id`: `5b6f`, `name`: `Bob`}}}, media.Examples)
+
+	panics(t, `[oas] example of type string doesn't match schema`, func() {
+		doc.MediaExample(&media, ``, `Ann`)
+	})
+
+	sch := doc.Sch(``)
+	doc.SchemaExample(&sch, `one`)
+	doc.SchemaExample(&sch, `two`)
+	eq(t, []any{`one`, `two`}, sch.Examples)
+
+	panics(t, `expected type string, got number`, func() { doc.SchemaExample(&sch, 10) })
+	panics(t, `[oas] failed to encode example of type func()`, func() { ExampleOf(func() {}) })
+	eq(t, json.Number(`9007199254740993`), ExampleOf(int64(9007199254740993)))
+}
+
+func TestDoc_Responses(t *testing.T) {
+	type ErrBody struct {
+		Msg string `json:"msg"`
+	}
+
+	var doc Doc
+	build := func() Resps {
+		return doc.Responses().
+			Created(Unit{}, ``).
+			NoContent(`Already exists.`).
+			Shared(`4XX`, `ClientError`, doc.JsonResp(ErrBody{}, ``)).
+			Json(`default`, ErrBody{}, `Unexpected error.`).
+			Ok(nil, ``).
+			Header(`200`, `X-Total`, Head{Schema: &Schema{Type: []string{TypeInt}}}).
+			Ref(`503`, `Unavailable`).
+			Done()
+	}
+	resps := build()
+
+	eq(t, Resps{
+		`200`: {Desc: `OK`, Head: Heads{`X-Total`: {Schema: &Schema{Type: []string{TypeInt}}}}},
+		`201`: {
+			Desc: `Created`,
+			Head: Heads{`Location`: {
+				Desc:   `URL of the created resource.`,
+				Requ:   true,
+				Schema: &Schema{Type: []string{TypeStr}, Format: `uri-reference`},
+			}},
+			Cont: MediaTypes{ConTypeJson: {Schema: RefSchema(`oas.Unit`)}},
+		},
+		`204`:     {Desc: `Already exists.`},
+		`4XX`:     {Ref: `#/components/responses/ClientError`},
+		`503`:     {Ref: `#/components/responses/Unavailable`},
+		`default`: {Desc: `Unexpected error.`, Cont: MediaTypes{ConTypeJson: {Schema: RefSchema(`oas.ErrBody`)}}},
+	}, resps)
+
+	eq(t, Resps{
+		`ClientError`: {Desc: `Client error`, Cont: MediaTypes{ConTypeJson: {Schema: RefSchema(`oas.ErrBody`)}}},
+	}, doc.Comps.Resps)
+
+	// Sharing an identical component again is fine.
+	eq(t, resps, build())
+
+	panics(t, `[oas] redundant non-identical response component "ClientError"`, func() {
+		doc.Responses().Shared(`400`, `ClientError`, Resp{})
+	})
+	panics(t, `[oas] redundant response for status "200"`, func() {
+		doc.Responses().Ok(nil, ``).NoContent(``).Json(`200`, nil, ``)
+	})
+	panics(t, `no non-reference response for status "404"`, func() {
+		doc.Responses().Header(`404`, `X-One`, Head{})
+	})
+
+	eq(t, `Not Found`, RespDesc(`404`))
+	eq(t, `Server error`, RespDesc(`5XX`))
+	eq(t, `Unexpected response`, RespDesc(`default`))
+	eq(t, `Response`, RespDesc(`599`))
+}
+
+func TestDoc_Problem(t *testing.T) {
+	type ProblemExt struct {
+		Code string `json:"code"`
+	}
+
+	var doc Doc
+	eq(t, RefSchema(`Problem`), doc.ProblemSchema())
+	eq(t, `about:blank`, doc.Comps.Schemas[`Problem`].Props[`type`].Default)
+
+	try(doc.Validate(doc.ProblemSchema(), map[string]any{`type`: `/probs/one`, `status`: 400, `extra`: true}))
+	eq(t, true, doc.Validate(doc.ProblemSchema(), map[string]any{`status`: 99}) != nil)
+
+	resps := doc.Responses().
+		Problem(`4XX`, nil, ``).
+		Problem(`409`, ProblemExt{}, `Conflict with code.`).
+		Done()
+
+	eq(t, Resps{
+		`4XX`: {Desc: `Client error`, Cont: MediaTypes{ConTypeProblem: {Schema: RefSchema(`Problem`)}}},
+		`409`: {
+			Desc: `Conflict with code.`,
+			Cont: MediaTypes{ConTypeProblem: {Schema: Schema{
+				AllOf: []Schema{RefSchema(`Problem`), RefSchema(`oas.ProblemExt`)},
+			}}},
+		},
+	}, resps)
+
+	doc.Route(`/one`, http.MethodGet, Op{Resps: doc.RespsOkJson(nil)})
+	doc.Route(`/two`, http.MethodGet, Op{Resps: Resps{`default`: {Desc: `Custom.`}}})
+	doc.Route(`/three`, http.MethodGet, Op{})
+	doc.DefaultProblems(nil)
+	doc.DefaultProblems(nil)
+
+	eq(t, Resp{Ref: `#/components/responses/Problem`}, doc.Paths[`/one`].Get.Resps[`default`])
+	eq(t, Resp{Desc: `Custom.`}, doc.Paths[`/two`].Get.Resps[`default`])
+	eq(t, Resps(nil), doc.Paths[`/three`].Get.Resps)
+	eq(t, `Unexpected error.`, doc.Comps.Resps[`Problem`].Desc)
+
+	panics(t, `non-identical response component "Problem"`, func() { doc.DefaultProblems(ProblemExt{}) })
+}
+
+func TestDoc_FormBody(t *testing.T) {
+	type Page struct {
+		Limit  uint64 `form:"limit"`
+		Offset uint64 `form:"offset"`
+	}
+	type Search struct {
+		Page
+		Query  string   `form:"q"`
+		Tags   []string `form:"tags"`
+		Hidden string   `form:"-"`
+		Plain  bool
+		hidden bool
+	}
+	type Upload struct {
+		Name    string                  `form:"name"`
+		Avatar  *multipart.FileHeader   `form:"avatar" contentType:"image/png"`
+		Photos  []*multipart.FileHeader `form:"photos" contentType:"image/png,image/jpeg"`
+		Raw     io.Reader               `form:"raw"`
+		Meta    Unit                    `form:"meta" contentType:"application/json"`
+		private bool
+	}
+
+	var doc Doc
+	str := doc.Sch(``)
+	num := doc.Sch(uint64(0))
+
+	eq(t, Body{Cont: MediaTypes{ConTypeForm: {Schema: Schema{
+		Type: []string{TypeObj},
+		Props: Schemas{
+			`limit`:  num,
+			`offset`: num,
+			`q`:      str,
+			`tags`:   doc.Sch([]string(nil)),
+			`Plain`:  doc.Sch(false),
+		},
+	}}}}, doc.FormBody(Search{}))
+
+	panics(t, `unable to describe file field "Avatar"`, func() { doc.FormBody(Upload{}) })
+	panics(t, `expected form struct type, got string`, func() { doc.FormBody(``) })
+
+	eq(t, Body{Cont: MediaTypes{ConTypeMultipart: {
+		Schema: Schema{
+			Type: []string{TypeObj},
+			Props: Schemas{
+				`name`:   str,
+				`avatar`: {Type: []string{TypeStr}, ContMedia: `image/png`},
+				`photos`: {Type: []string{TypeArr}, Items: &Schema{Type: []string{TypeStr}, ContMedia: ConTypeBin}},
+				`raw`:    {Type: []string{TypeStr}, ContMedia: ConTypeBin},
+				`meta`:   RefSchema(`oas.Unit`),
+			},
+		},
+		Encoding: Encodings{
+			`avatar`: {ConType: `image/png`, Head: Heads{`X-Custom`: {Schema: str.Opt()}}},
+			`photos`: {ConType: `image/png,image/jpeg`},
+			`raw`:    {ConType: ConTypeBin},
+			`meta`:   {ConType: ConTypeJson},
+			`name`:   {ConType: `text/plain; charset=utf-8`},
+		},
+	}}}, doc.MultipartBody(Upload{}, Encodings{
+		`avatar`: {Head: Heads{`X-Custom`: {Schema: str.Opt()}}},
+		`name`:   {ConType: `text/plain; charset=utf-8`},
+	}))
+}
+
+func TestBinResp(t *testing.T) {
+	eq(t, Body{Requ: true, Cont: MediaTypes{ConTypeBin: {}}}, BinBody())
+	eq(t, Body{Requ: true, Cont: MediaTypes{`image/png`: {}, `image/jpeg`: {}}}, BinBody(`image/png`, `image/jpeg`))
+
+	eq(t,
+		Schema{Type: []string{TypeStr}, ContEnc: `base64`, ContMedia: `image/png`},
+		Base64Schema(`image/png`),
+	)
+
+	var doc Doc
+	resps := doc.Responses().
+		Bin(`200`, ``, `image/png`).
+		File(`201`, `CSV report.`, `text/csv`).
+		Done()
+
+	eq(t, Resp{Desc: `Binary content.`, Cont: MediaTypes{`image/png`: {}}}, resps[`200`])
+	eq(t, `CSV report.`, resps[`201`].Desc)
+	eq(t, MediaTypes{`text/csv`: {}}, resps[`201`].Cont)
+	eq(t, true, resps[`201`].Head[HeadConDisp].Requ)
+
+	src, err := json.Marshal(BinResp(``))
+	try(err)
+	eq(t, `{"description":"Binary content.","content":{"application/octet-stream":{}}}`, string(src))
+}
+
+func TestDoc_StreamMedia(t *testing.T) {
+	var doc Doc
+	eq(t, MediaType{Exts: Anys{ExtItemSchema: RefSchema(`oas.Unit`)}}, doc.StreamMedia(Unit{}))
+
+	doc.Route(`/units`, http.MethodGet, Op{Resps: Resps{`200`: doc.StreamResp(Unit{}, ``)}})
+	eq(t, []string(nil), doc.Prune())
+	eq(t, []string{`oas.Unit`}, sortedKeys(doc.Comps.Schemas))
+
+	var decoded Doc
+	try(json.Unmarshal([]byte(jsonStr(doc)), &decoded))
+	eq(t, doc, decoded)
+	eq(t, []string(nil), decoded.Prune())
+	eq(t, map[string]bool{`#/components/schemas/oas.Unit`: true}, decoded.Reachable())
+
+	doc = Doc{Openapi: Ver32}
+	eq(t, MediaType{ItemSchema: RefSchema(`oas.Unit`).Opt()}, doc.StreamMedia(Unit{}))
+
+	resp := doc.StreamResp(Unit{}, ``, ConTypeNdjson, ConTypeJsonl)
+	eq(t, `Stream of items.`, resp.Desc)
+	eq(t, []string{ConTypeJsonl, ConTypeNdjson}, sortedKeys(resp.Cont))
+
+	doc.Route(`/units`, http.MethodGet, Op{Resps: Resps{`200`: resp}})
+	eq(t, []string(nil), doc.Prune())
+
+	src, err := json.Marshal(doc.Paths[`/units`].Get.Resps[`200`].Cont[ConTypeNdjson])
+	try(err)
+	eq(t, `{"itemSchema":{"$ref":"#/components/schemas/oas.Unit"}}`, string(src))
+
+	media := doc.SseMedia(SseEvent{Event: `unit`, Data: Unit{}}, SseEvent{Desc: `Plain text.`})
+	eq(t, 2, len(media.ItemSchema.OneOf))
+
+	named := media.ItemSchema.OneOf[0]
+	eq(t, []string{`data`, `event`}, named.Requ)
+	eq(t, `unit`, named.Props[`event`].Const)
+	eq(t, Schema{Type: []string{TypeStr}, ContMedia: ConTypeJson, ContSchema: RefSchema(`oas.Unit`).Opt()}, named.Props[`data`])
+
+	plain := media.ItemSchema.OneOf[1]
+	eq(t, `Plain text.`, plain.Desc)
+	eq(t, []string{`data`}, plain.Requ)
+	eq(t, Schema{Type: []string{TypeStr}}, plain.Props[`data`])
+
+	try(doc.Validate(named, map[string]any{`event`: `unit`, `data`: `{}`, `id`: `1`}))
+	eq(t, true, doc.Validate(named, map[string]any{`event`: `other`, `data`: `{}`}) != nil)
+
+	resps := doc.Responses().Sse(`200`, ``).Stream(`206`, Unit{}, `Partial.`).Done()
+	eq(t, []string{ConTypeSse}, sortedKeys(resps[`200`].Cont))
+	eq(t, []string{ConTypeNdjson}, sortedKeys(resps[`206`].Cont))
+}
+
+func TestDoc_Media(t *testing.T) {
+	type Addr struct {
+		City string `json:"city" xml:"city"`
+	}
+	type Person struct {
+		XMLName xml.Name `json:"-" xml:"urn:people person"`
+		Id      string   `json:"id" xml:"id,attr"`
+		Name    string   `json:"name" xml:"fullName"`
+		Tags    []string `json:"tags" xml:"tags>tag"`
+		Nicks   []string `json:"nicks" xml:"nick"`
+		Addr    *Addr    `json:"addr"`
+		Note    string   `json:"note" xml:",chardata"`
+		Skip    string   `json:"skip" xml:"-"`
+	}
+
+	var doc Doc
+	cont := doc.Media(Person{}, ConTypeJson, ConTypeXml, ConTypeCsv, `application/pdf`)
+
+	eq(t, MediaTypes{
+		ConTypeJson:       {Schema: RefSchema(`oas.Person`)},
+		ConTypeXml:        {Schema: RefSchema(`oas.Person`)},
+		ConTypeCsv:        {Schema: Schema{Type: []string{TypeStr}}},
+		`application/pdf`: {},
+	}, cont)
+
+	sch, _ := doc.GotCompSchema(`oas.Person`)
+	eq(t, &Xml{Name: `person`, Nspace: `urn:people`}, sch.Xml)
+	eq(t, &Xml{Attr: true}, sch.Props[`id`].Xml)
+	eq(t, &Xml{Name: `fullName`}, sch.Props[`name`].Xml)
+	eq(t, &Xml{Name: `Addr`}, sch.Props[`addr`].Xml)
+	eq(t, (*Xml)(nil), sch.Props[`note`].Xml)
+	eq(t, (*Xml)(nil), sch.Props[`skip`].Xml)
+
+	strs := doc.Sch([]string(nil))
+	item := doc.Sch(``)
+
+	tags := sch.Props[`tags`]
+	eq(t, ``, tags.Ref)
+	eq(t, &Xml{Name: `tags`, Wrap: true}, tags.Xml)
+	eq(t, &Xml{Name: `tag`}, tags.Items.Xml)
+	eq(t, item.Type, tags.Items.Type)
+
+	nicks := sch.Props[`nicks`]
+	eq(t, (*Xml)(nil), nicks.Xml)
+	eq(t, &Xml{Name: `nick`}, nicks.Items.Xml)
+
+	// The shared component of the slice type is not modified.
+	eq(t, strs, RefSchema(`[]string`))
+	comp, _ := doc.GotCompSchema(`[]string`)
+	eq(t, (*Xml)(nil), comp.Items.Xml)
+
+	addr, _ := doc.GotCompSchema(`oas.Addr`)
+	eq(t, &Xml{Name: `Addr`}, addr.Xml)
+	eq(t, (*Xml)(nil), addr.Props[`city`].Xml)
+
+	resps := doc.Responses().Media(`200`, Addr{}, ``, ConTypeXml, `text/xml`).Done()
+	eq(t, Resp{Desc: `OK`, Cont: MediaTypes{
+		ConTypeXml: {Schema: RefSchema(`oas.Addr`)},
+		`text/xml`: {Schema: RefSchema(`oas.Addr`)},
+	}}, resps[`200`])
+
+	eq(t, MediaTypes{ConTypeJson: {Schema: RefSchema(`oas.Addr`)}}, doc.Media(Addr{}))
+
+	eq(t, true, IsConTypeXml(`application/atom+xml; charset=utf-8`))
+	eq(t, true, IsConTypeXml(`text/xml`))
+	eq(t, false, IsConTypeXml(ConTypeJson))
+}
+
+func TestDoc_Operation(t *testing.T) {
+	var doc Doc
+	str := doc.Sch(``)
+
+	doc.Operation(`/persons/{id}/user-groups`, http.MethodGet).
+		Tags(`persons`).
+		PathParam(`id`, ``, `Person id.`).
+		Query(`limit`, uint64(0), ``).
+		Resps(doc.Responses().Ok([]string(nil), ``).Done()).
+		Done().
+		Operation(`/persons`, http.MethodGet).
+		Func(getPersons).
+		Done().
+		Operation(`/persons`, http.MethodPost).
+		Func(opHandlers{}.CreatePerson).
+		Sum(`Create a person`).
+		Json(Unit{}).
+		Sec(SecReq{`token`: nil}).
+		Depr().
+		Done().
+		Operation(`/status`, http.MethodGet).
+		Id(`getHTTPStatus`).
+		Header(`X-Trace`, ``, `Trace id.`).
+		Done()
+
+	eq(t, &Op{
+		Sum:  `Get persons by id user groups`,
+		Tags: []string{`persons`},
+		OpId: `getPersonsByIdUserGroups`,
+		Params: []Param{
+			{Name: `id`, In: InPath, Head: Head{Desc: `Person id.`, Requ: true, Schema: str.Opt()}},
+			{Name: `limit`, In: InQuery, Head: Head{Schema: doc.Sch(uint64(0)).Opt()}},
+		},
+		Resps: Resps{`200`: doc.JsonResp([]string(nil), `OK`)},
+	}, doc.Paths[`/persons/{id}/user-groups`].Get)
+
+	eq(t, &Op{Sum: `Get persons`, OpId: `getPersons`}, doc.Paths[`/persons`].Get)
+
+	eq(t, &Op{
+		Sum:     `Create a person`,
+		OpId:    `CreatePerson`,
+		ReqBody: doc.JsonBody(Unit{}).Opt(),
+		Sec:     []SecReq{{`token`: nil}},
+		Depr:    true,
+	}, doc.Paths[`/persons`].Post)
+
+	eq(t, `Get HTTP status`, doc.Paths[`/status`].Get.Sum)
+	eq(t, InHeader, doc.Paths[`/status`].Get.Params[0].In)
+
+	panics(t, `operationId "getPersons" of PUT /persons is already used at "/paths/~1persons/get"`, func() {
+		doc.Operation(`/persons`, http.MethodPut).Id(`getPersons`).Done()
+	})
+	panics(t, `unable to derive operationId from anonymous function`, func() {
+		doc.Operation(`/persons`, http.MethodPut).Func(func() {}).Done()
+	})
+	panics(t, `unable to derive operationId from non-function string`, func() {
+		doc.Operation(`/persons`, http.MethodPut).Func(``).Done()
+	})
+
+	panics(t, `duplicate route GET /persons`, func() {
+		doc.Operation(`/persons`, http.MethodGet).Func(getPersons).Done()
+	})
+
+	eq(t, `get`, opIdPath(http.MethodGet, `/`))
+	eq(t, `deleteV1ItemsByItemIdTags`, opIdPath(http.MethodDelete, `/v1/items/{item_id}/tags`))
+}
+
+func TestPaths_Route_duplicate(t *testing.T) {
+	var doc Doc
+	doc.Route(`/users/{id}`, http.MethodGet, Op{Desc: `one`})
+
+	panics(t, `[oas] duplicate route GET /users/{id}`, func() {
+		doc.Route(`/users/{id}`, http.MethodGet, Op{Desc: `two`})
+	})
+	eq(t, `one`, doc.Paths[`/users/{id}`].Get.Desc)
+
+	eq(t,
+		`[oas] path "/users/{userId}" is identical to registered path "/users/{id}", differing only in parameter names`,
+		doc.TryRoute(`/users/{userId}`, http.MethodPut, Op{}).Error(),
+	)
+	eq(t, []string{`/users/{id}`}, sortedKeys(doc.Paths))
+
+	try(doc.TryRoute(`/users/{id}`, http.MethodPut, Op{}))
+	try(doc.TryRoute(`/users/{id}/posts`, http.MethodGet, Op{}))
+	eq(t, []string{http.MethodGet, http.MethodPut}, doc.Paths[`/users/{id}`].Methods())
+
+	// Replacing an operation deliberately.
+	item := doc.Paths[`/users/{id}`]
+	item.Method(http.MethodGet, Op{Desc: `three`})
+	eq(t, `three`, item.Get.Desc)
+}
+
+func TestPath_AddOps(t *testing.T) {
+	var item Path
+	item.Method(`PURGE`, Op{Desc: `purge`}).
+		Method(MethodQuery, Op{Desc: `query`}).
+		Method(`PROPFIND`, Op{Desc: `propfind`}).
+		Method(http.MethodGet, Op{Desc: `get`})
+
+	eq(t, `query`, item.Query.Desc)
+	eq(t, []string{`PROPFIND`, `PURGE`}, sortedKeys(item.AddOps))
+	eq(t, []string{http.MethodGet, MethodQuery, `PROPFIND`, `PURGE`}, item.Methods())
+	eq(t, `purge`, item.Op(`PURGE`).Desc)
+	eq(t, (*Op)(nil), item.Op(`purge`))
+
+	var descs []string
+	item.Each(func(meth string, op *Op) { descs = append(descs, meth+`:`+op.Desc) })
+	eq(t, []string{`GET:get`, `QUERY:query`, `PROPFIND:propfind`, `PURGE:purge`}, descs)
+
+	panics(t, `[oas] invalid method "BAD METHOD"`, func() { item.Method(`BAD METHOD`, Op{}) })
+	panics(t, `[oas] unrecognized method "get"; did you mean "GET"?`, func() { item.Method(`get`, Op{}) })
+
+	src, err := json.Marshal(item)
+	try(err)
+	eq(t,
+		`{"get":{"description":"get"},"query":{"description":"query"},"additionalOperations":{"PROPFIND":{"description":"propfind"},"PURGE":{"description":"purge"}}}`,
+		string(src),
+	)
+
+	var dec Path
+	try(json.Unmarshal(src, &dec))
+	eq(t, item, dec)
+}
+
+func TestDoc_Route_AddOps(t *testing.T) {
+	doc := Doc{Openapi: Ver}
+	eq(t,
+		`[oas] method "QUERY" requires OpenAPI 3.2.0, but the document targets 3.1.0`,
+		doc.TryRoute(`/items`, MethodQuery, Op{}).Error(),
+	)
+	panics(t, `method "PURGE" requires OpenAPI 3.2.0`, func() { doc.Route(`/items`, `PURGE`, Op{}) })
+	eq(t, 0, len(doc.Paths))
+
+	// Paths don't know the version; the lint rule reports the same problem.
+	doc.Paths.Init().Route(`/items`, `PURGE`, Op{})
+	eq(t, Diags{{
+		Rule: `op-version`,
+		Sev:  SevError,
+		Ptr:  `/paths/~1items/additionalOperations/PURGE`,
+		Msg:  `method "PURGE" requires OpenAPI 3.2.0, but the document targets 3.1.0`,
+	}}, Lint(&doc, LintOpt{Skip: []string{`op-desc`, `op-id-missing`, `op-success`, `info-version`}}))
+	doc.Paths = nil
+
+	doc.Openapi = Ver32
+	doc.Route(`/items`, MethodQuery, Op{OpId: `queryItems`, Resps: Resps{`200`: {Desc: `OK`}}})
+	doc.Route(`/items`, `PURGE`, Op{OpId: `purgeItems`})
+	panics(t, `duplicate route PURGE /items`, func() { doc.Route(`/items`, `PURGE`, Op{}) })
+
+	var ptrs []string
+	_ = doc.Walk(func(ptr string, val any) error {
+		if _, ok := val.(*Op); ok {
+			ptrs = append(ptrs, ptr)
+		}
+		return nil
+	})
+	eq(t, []string{`/paths/~1items/query`, `/paths/~1items/additionalOperations/PURGE`}, ptrs)
+
+	var descs Diags
+	for _, diag := range Lint(&doc, LintOpt{}) {
+		if diag.Rule == `op-desc` {
+			descs = append(descs, diag)
+		}
+	}
+	eq(t, `/paths/~1items/additionalOperations/PURGE`, descs[len(descs)-1].Ptr)
+	eq(t, 2, len(descs))
+
+	out := doc.Filter(func(_, meth string, _ Op) bool { return meth != `PURGE` })
+	eq(t, []string{MethodQuery}, out.Paths[`/items`].Methods())
+	eq(t, []string{MethodQuery, `PURGE`}, doc.Paths[`/items`].Methods())
+
+	var merged Doc
+	try(merged.Merge(doc, MergeOpt{}))
+	eq(t, []string{MethodQuery, `PURGE`}, merged.Paths[`/items`].Methods())
+
+	changes := Diff(&doc, &out)
+	eq(t, 1, len(changes))
+	eq(t, `op-removed`, changes[0].Code)
+	eq(t, `/paths/~1items/additionalOperations/PURGE`, changes[0].Ptr)
+}
+
+func TestPaths_Match(t *testing.T) {
+	paths := Paths{
+		`/users`:                  {},
+		`/users/me`:               {},
+		`/users/{id}`:             {},
+		`/users/{id}/files/{key}`: {},
+		`/files/{name}.{ext}`:     {},
+		`/{any}/me`:               {},
+	}
+
+	test := func(path, expTpl string, expParams map[string]string) {
+		t.Helper()
+		tpl, params, ok := paths.Match(path)
+		eq(t, expTpl != ``, ok)
+		eq(t, expTpl, tpl)
+		eq(t, expParams, params)
+	}
+
+	test(`/users`, `/users`, map[string]string{})
+	test(`/users/me`, `/users/me`, map[string]string{})
+	test(`/users/123`, `/users/{id}`, map[string]string{`id`: `123`})
+	test(`/users/a%2Fb/files/c%20d`, `/users/{id}/files/{key}`, map[string]string{`id`: `a/b`, `key`: `c d`})
+	test(`/files/one.two.json`, `/files/{name}.{ext}`, map[string]string{`name`: `one`, `ext`: `two.json`})
+	test(`/groups/me`, `/{any}/me`, map[string]string{`any`: `groups`})
+	test(`/users/`, ``, nil)
+	test(`/users/123/files`, ``, nil)
+	test(`/files/.json`, ``, nil)
+	test(`/other`, ``, nil)
+}
+
+func TestMediaTypes_Match(t *testing.T) {
+	types := MediaTypes{
+		`application/json; charset=utf-8`: {Desc: `json`},
+		`text/*`:                          {Desc: `text`},
+		`*/*`:                             {Desc: `any`},
+	}
+
+	test := func(conType, expKey string) {
+		t.Helper()
+		key, val, ok := types.Match(conType)
+		eq(t, expKey, key)
+		eq(t, ok, expKey != ``)
+		eq(t, types[expKey], val)
+	}
+
+	test(`application/json`, `application/json; charset=utf-8`)
+	test(`Application/JSON;charset=latin1`, `application/json; charset=utf-8`)
+	test(`text/csv`, `text/*`)
+	test(`image/png`, `*/*`)
+	test(``, ``)
+
+	eq(t, true, IsConTypeJson(`application/json; charset=utf-8`))
+	eq(t, true, IsConTypeJson(`application/problem+json`))
+	eq(t, false, IsConTypeJson(`text/plain`))
+}
+
+func Test_lintGoName(t *testing.T) {
+	for _, val := range []string{`oas.Unit`, `[]oas.Unit`, `*main.Person`, `map[string]int`, `Page[main.Person]`, `Type main.Person`} {
+		eq(t, true, lintGoName.MatchString(val))
+	}
+	for _, val := range []string{`Unit`, `example.com`, `api.example.com`, `Api.Example`, `v1.2`, `see https://api.Example.com`} {
+		eq(t, false, lintGoName.MatchString(val))
+	}
+}
+
 func Test_nonZero(t *testing.T) {
 	test := func(ok bool, exp interface{}) {
 		t.Helper()